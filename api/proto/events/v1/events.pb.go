@@ -0,0 +1,961 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.5
+// source: api/proto/events/v1/events.proto
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Envelope wraps every event published by the URL service. Topics encoded
+// as protobuf carry a serialized Envelope as the Kafka message value.
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                             // UUIDv7, unique per event; consumers dedupe on it
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                         // url_created, url_updated, ...
+	SchemaVersion int32                  `protobuf:"varint,3,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"` // version of the payload schema for type
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Producer      string                 `protobuf:"bytes,5,opt,name=producer,proto3" json:"producer,omitempty"` // instance that produced the event
+	Trace         *TraceContext          `protobuf:"bytes,6,opt,name=trace,proto3" json:"trace,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*Envelope_UrlCreated
+	//	*Envelope_UrlUpdated
+	//	*Envelope_UrlClicked
+	//	*Envelope_UrlDeleted
+	//	*Envelope_UrlRestored
+	//	*Envelope_UrlPurged
+	Data          isEnvelope_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Envelope) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Envelope) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Envelope) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *Envelope) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *Envelope) GetTrace() *TraceContext {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+func (x *Envelope) GetData() isEnvelope_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlCreated() *URLCreated {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlCreated); ok {
+			return x.UrlCreated
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlUpdated() *URLUpdated {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlUpdated); ok {
+			return x.UrlUpdated
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlClicked() *URLClicked {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlClicked); ok {
+			return x.UrlClicked
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlDeleted() *URLDeleted {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlDeleted); ok {
+			return x.UrlDeleted
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlRestored() *URLRestored {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlRestored); ok {
+			return x.UrlRestored
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetUrlPurged() *URLPurged {
+	if x != nil {
+		if x, ok := x.Data.(*Envelope_UrlPurged); ok {
+			return x.UrlPurged
+		}
+	}
+	return nil
+}
+
+type isEnvelope_Data interface {
+	isEnvelope_Data()
+}
+
+type Envelope_UrlCreated struct {
+	UrlCreated *URLCreated `protobuf:"bytes,10,opt,name=url_created,json=urlCreated,proto3,oneof"`
+}
+
+type Envelope_UrlUpdated struct {
+	UrlUpdated *URLUpdated `protobuf:"bytes,11,opt,name=url_updated,json=urlUpdated,proto3,oneof"`
+}
+
+type Envelope_UrlClicked struct {
+	UrlClicked *URLClicked `protobuf:"bytes,12,opt,name=url_clicked,json=urlClicked,proto3,oneof"`
+}
+
+type Envelope_UrlDeleted struct {
+	UrlDeleted *URLDeleted `protobuf:"bytes,13,opt,name=url_deleted,json=urlDeleted,proto3,oneof"`
+}
+
+type Envelope_UrlRestored struct {
+	UrlRestored *URLRestored `protobuf:"bytes,14,opt,name=url_restored,json=urlRestored,proto3,oneof"`
+}
+
+type Envelope_UrlPurged struct {
+	UrlPurged *URLPurged `protobuf:"bytes,15,opt,name=url_purged,json=urlPurged,proto3,oneof"`
+}
+
+func (*Envelope_UrlCreated) isEnvelope_Data() {}
+
+func (*Envelope_UrlUpdated) isEnvelope_Data() {}
+
+func (*Envelope_UrlClicked) isEnvelope_Data() {}
+
+func (*Envelope_UrlDeleted) isEnvelope_Data() {}
+
+func (*Envelope_UrlRestored) isEnvelope_Data() {}
+
+func (*Envelope_UrlPurged) isEnvelope_Data() {}
+
+// TraceContext is the W3C trace context of the request that caused the
+// event
+type TraceContext struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Traceparent   string                 `protobuf:"bytes,1,opt,name=traceparent,proto3" json:"traceparent,omitempty"`
+	Tracestate    string                 `protobuf:"bytes,2,opt,name=tracestate,proto3" json:"tracestate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraceContext) Reset() {
+	*x = TraceContext{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceContext) ProtoMessage() {}
+
+func (x *TraceContext) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceContext.ProtoReflect.Descriptor instead.
+func (*TraceContext) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TraceContext) GetTraceparent() string {
+	if x != nil {
+		return x.Traceparent
+	}
+	return ""
+}
+
+func (x *TraceContext) GetTracestate() string {
+	if x != nil {
+		return x.Tracestate
+	}
+	return ""
+}
+
+type URLCreated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLCreated) Reset() {
+	*x = URLCreated{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLCreated) ProtoMessage() {}
+
+func (x *URLCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLCreated.ProtoReflect.Descriptor instead.
+func (*URLCreated) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *URLCreated) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLCreated) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URLCreated) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *URLCreated) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type URLUpdated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IsActive      bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	UpdatedFields []string               `protobuf:"bytes,7,rep,name=updated_fields,json=updatedFields,proto3" json:"updated_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLUpdated) Reset() {
+	*x = URLUpdated{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLUpdated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLUpdated) ProtoMessage() {}
+
+func (x *URLUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLUpdated.ProtoReflect.Descriptor instead.
+func (*URLUpdated) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *URLUpdated) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLUpdated) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URLUpdated) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *URLUpdated) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *URLUpdated) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *URLUpdated) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *URLUpdated) GetUpdatedFields() []string {
+	if x != nil {
+		return x.UpdatedFields
+	}
+	return nil
+}
+
+type URLClicked struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode      string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserAgent      string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress      string                 `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Referrer       string                 `protobuf:"bytes,4,opt,name=referrer,proto3" json:"referrer,omitempty"`
+	Country        string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"` // ISO 3166-1 alpha-2, empty when unknown
+	Region         string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`   // ISO 3166-2 subdivision code
+	City           string                 `protobuf:"bytes,7,opt,name=city,proto3" json:"city,omitempty"`
+	DeviceType     string                 `protobuf:"bytes,8,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"` // desktop, mobile, tablet or bot; empty when unknown
+	Os             string                 `protobuf:"bytes,9,opt,name=os,proto3" json:"os,omitempty"`
+	OsVersion      string                 `protobuf:"bytes,10,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`
+	Browser        string                 `protobuf:"bytes,11,opt,name=browser,proto3" json:"browser,omitempty"`
+	BrowserVersion string                 `protobuf:"bytes,12,opt,name=browser_version,json=browserVersion,proto3" json:"browser_version,omitempty"`
+	IsBot          bool                   `protobuf:"varint,13,opt,name=is_bot,json=isBot,proto3" json:"is_bot,omitempty"` // automated click, not counted in click_count
+	BotReason      string                 `protobuf:"bytes,14,opt,name=bot_reason,json=botReason,proto3" json:"bot_reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *URLClicked) Reset() {
+	*x = URLClicked{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLClicked) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLClicked) ProtoMessage() {}
+
+func (x *URLClicked) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLClicked.ProtoReflect.Descriptor instead.
+func (*URLClicked) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *URLClicked) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLClicked) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *URLClicked) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *URLClicked) GetReferrer() string {
+	if x != nil {
+		return x.Referrer
+	}
+	return ""
+}
+
+func (x *URLClicked) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *URLClicked) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *URLClicked) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *URLClicked) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *URLClicked) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *URLClicked) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *URLClicked) GetBrowser() string {
+	if x != nil {
+		return x.Browser
+	}
+	return ""
+}
+
+func (x *URLClicked) GetBrowserVersion() string {
+	if x != nil {
+		return x.BrowserVersion
+	}
+	return ""
+}
+
+func (x *URLClicked) GetIsBot() bool {
+	if x != nil {
+		return x.IsBot
+	}
+	return false
+}
+
+func (x *URLClicked) GetBotReason() string {
+	if x != nil {
+		return x.BotReason
+	}
+	return ""
+}
+
+type URLDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"` // user_delete, expiry_sweep or abuse_takedown
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLDeleted) Reset() {
+	*x = URLDeleted{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLDeleted) ProtoMessage() {}
+
+func (x *URLDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLDeleted.ProtoReflect.Descriptor instead.
+func (*URLDeleted) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *URLDeleted) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLDeleted) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URLDeleted) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *URLDeleted) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *URLDeleted) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+type URLRestored struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLRestored) Reset() {
+	*x = URLRestored{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLRestored) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLRestored) ProtoMessage() {}
+
+func (x *URLRestored) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLRestored.ProtoReflect.Descriptor instead.
+func (*URLRestored) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *URLRestored) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLRestored) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URLRestored) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *URLRestored) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type URLPurged struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLPurged) Reset() {
+	*x = URLPurged{}
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLPurged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLPurged) ProtoMessage() {}
+
+func (x *URLPurged) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_events_v1_events_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLPurged.ProtoReflect.Descriptor instead.
+func (*URLPurged) Descriptor() ([]byte, []int) {
+	return file_api_proto_events_v1_events_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *URLPurged) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLPurged) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *URLPurged) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+var File_api_proto_events_v1_events_proto protoreflect.FileDescriptor
+
+const file_api_proto_events_v1_events_proto_rawDesc = "" +
+	"\n" +
+	" api/proto/events/v1/events.proto\x12\rurl.events.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xdd\x04\n" +
+	"\bEnvelope\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12%\n" +
+	"\x0eschema_version\x18\x03 \x01(\x05R\rschemaVersion\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x1a\n" +
+	"\bproducer\x18\x05 \x01(\tR\bproducer\x121\n" +
+	"\x05trace\x18\x06 \x01(\v2\x1b.url.events.v1.TraceContextR\x05trace\x12<\n" +
+	"\vurl_created\x18\n" +
+	" \x01(\v2\x19.url.events.v1.URLCreatedH\x00R\n" +
+	"urlCreated\x12<\n" +
+	"\vurl_updated\x18\v \x01(\v2\x19.url.events.v1.URLUpdatedH\x00R\n" +
+	"urlUpdated\x12<\n" +
+	"\vurl_clicked\x18\f \x01(\v2\x19.url.events.v1.URLClickedH\x00R\n" +
+	"urlClicked\x12<\n" +
+	"\vurl_deleted\x18\r \x01(\v2\x19.url.events.v1.URLDeletedH\x00R\n" +
+	"urlDeleted\x12?\n" +
+	"\furl_restored\x18\x0e \x01(\v2\x1a.url.events.v1.URLRestoredH\x00R\vurlRestored\x129\n" +
+	"\n" +
+	"url_purged\x18\x0f \x01(\v2\x18.url.events.v1.URLPurgedH\x00R\turlPurgedB\x06\n" +
+	"\x04data\"P\n" +
+	"\fTraceContext\x12 \n" +
+	"\vtraceparent\x18\x01 \x01(\tR\vtraceparent\x12\x1e\n" +
+	"\n" +
+	"tracestate\x18\x02 \x01(\tR\n" +
+	"tracestate\"\xa2\x01\n" +
+	"\n" +
+	"URLCreated\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x9b\x02\n" +
+	"\n" +
+	"URLUpdated\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tis_active\x18\x04 \x01(\bR\bisActive\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12%\n" +
+	"\x0eupdated_fields\x18\a \x03(\tR\rupdatedFields\"\x94\x03\n" +
+	"\n" +
+	"URLClicked\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tR\tipAddress\x12\x1a\n" +
+	"\breferrer\x18\x04 \x01(\tR\breferrer\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x12\x12\n" +
+	"\x04city\x18\a \x01(\tR\x04city\x12\x1f\n" +
+	"\vdevice_type\x18\b \x01(\tR\n" +
+	"deviceType\x12\x0e\n" +
+	"\x02os\x18\t \x01(\tR\x02os\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\n" +
+	" \x01(\tR\tosVersion\x12\x18\n" +
+	"\abrowser\x18\v \x01(\tR\abrowser\x12'\n" +
+	"\x0fbrowser_version\x18\f \x01(\tR\x0ebrowserVersion\x12\x15\n" +
+	"\x06is_bot\x18\r \x01(\bR\x05isBot\x12\x1d\n" +
+	"\n" +
+	"bot_reason\x18\x0e \x01(\tR\tbotReason\"\xba\x01\n" +
+	"\n" +
+	"URLDeleted\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x129\n" +
+	"\n" +
+	"deleted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\"\xa3\x01\n" +
+	"\vURLRestored\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"~\n" +
+	"\tURLPurged\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x129\n" +
+	"\n" +
+	"deleted_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAtB,Z*url-shortener/api/proto/events/v1;eventspbb\x06proto3"
+
+var (
+	file_api_proto_events_v1_events_proto_rawDescOnce sync.Once
+	file_api_proto_events_v1_events_proto_rawDescData []byte
+)
+
+func file_api_proto_events_v1_events_proto_rawDescGZIP() []byte {
+	file_api_proto_events_v1_events_proto_rawDescOnce.Do(func() {
+		file_api_proto_events_v1_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_events_v1_events_proto_rawDesc), len(file_api_proto_events_v1_events_proto_rawDesc)))
+	})
+	return file_api_proto_events_v1_events_proto_rawDescData
+}
+
+var file_api_proto_events_v1_events_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_proto_events_v1_events_proto_goTypes = []any{
+	(*Envelope)(nil),              // 0: url.events.v1.Envelope
+	(*TraceContext)(nil),          // 1: url.events.v1.TraceContext
+	(*URLCreated)(nil),            // 2: url.events.v1.URLCreated
+	(*URLUpdated)(nil),            // 3: url.events.v1.URLUpdated
+	(*URLClicked)(nil),            // 4: url.events.v1.URLClicked
+	(*URLDeleted)(nil),            // 5: url.events.v1.URLDeleted
+	(*URLRestored)(nil),           // 6: url.events.v1.URLRestored
+	(*URLPurged)(nil),             // 7: url.events.v1.URLPurged
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 9: google.protobuf.Struct
+}
+var file_api_proto_events_v1_events_proto_depIdxs = []int32{
+	8,  // 0: url.events.v1.Envelope.occurred_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: url.events.v1.Envelope.trace:type_name -> url.events.v1.TraceContext
+	2,  // 2: url.events.v1.Envelope.url_created:type_name -> url.events.v1.URLCreated
+	3,  // 3: url.events.v1.Envelope.url_updated:type_name -> url.events.v1.URLUpdated
+	4,  // 4: url.events.v1.Envelope.url_clicked:type_name -> url.events.v1.URLClicked
+	5,  // 5: url.events.v1.Envelope.url_deleted:type_name -> url.events.v1.URLDeleted
+	6,  // 6: url.events.v1.Envelope.url_restored:type_name -> url.events.v1.URLRestored
+	7,  // 7: url.events.v1.Envelope.url_purged:type_name -> url.events.v1.URLPurged
+	8,  // 8: url.events.v1.URLCreated.expires_at:type_name -> google.protobuf.Timestamp
+	8,  // 9: url.events.v1.URLUpdated.expires_at:type_name -> google.protobuf.Timestamp
+	9,  // 10: url.events.v1.URLUpdated.metadata:type_name -> google.protobuf.Struct
+	8,  // 11: url.events.v1.URLDeleted.deleted_at:type_name -> google.protobuf.Timestamp
+	8,  // 12: url.events.v1.URLRestored.expires_at:type_name -> google.protobuf.Timestamp
+	8,  // 13: url.events.v1.URLPurged.deleted_at:type_name -> google.protobuf.Timestamp
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_events_v1_events_proto_init() }
+func file_api_proto_events_v1_events_proto_init() {
+	if File_api_proto_events_v1_events_proto != nil {
+		return
+	}
+	file_api_proto_events_v1_events_proto_msgTypes[0].OneofWrappers = []any{
+		(*Envelope_UrlCreated)(nil),
+		(*Envelope_UrlUpdated)(nil),
+		(*Envelope_UrlClicked)(nil),
+		(*Envelope_UrlDeleted)(nil),
+		(*Envelope_UrlRestored)(nil),
+		(*Envelope_UrlPurged)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_events_v1_events_proto_rawDesc), len(file_api_proto_events_v1_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_proto_events_v1_events_proto_goTypes,
+		DependencyIndexes: file_api_proto_events_v1_events_proto_depIdxs,
+		MessageInfos:      file_api_proto_events_v1_events_proto_msgTypes,
+	}.Build()
+	File_api_proto_events_v1_events_proto = out.File
+	file_api_proto_events_v1_events_proto_goTypes = nil
+	file_api_proto_events_v1_events_proto_depIdxs = nil
+}
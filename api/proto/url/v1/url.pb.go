@@ -27,6 +27,7 @@ type CreateURLRequest struct {
 	UserId        *int64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
 	ExpiresIn     *int64                 `protobuf:"varint,3,opt,name=expires_in,json=expiresIn,proto3,oneof" json:"expires_in,omitempty"` // seconds
 	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CustomAlias   *string                `protobuf:"bytes,5,opt,name=custom_alias,json=customAlias,proto3,oneof" json:"custom_alias,omitempty"` // vanity short code chosen by the user
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,6 +90,13 @@ func (x *CreateURLRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *CreateURLRequest) GetCustomAlias() string {
+	if x != nil && x.CustomAlias != nil {
+		return *x.CustomAlias
+	}
+	return ""
+}
+
 type GetURLRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
@@ -140,7 +148,8 @@ type URLResponse struct {
 	OriginalUrl   string                 `protobuf:"bytes,3,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
 	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	ExpiresAt     *int64                 `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3,oneof" json:"expires_at,omitempty"`
-	ClickCount    int64                  `protobuf:"varint,6,opt,name=click_count,json=clickCount,proto3" json:"click_count,omitempty"`
+	ClickCount    int64                  `protobuf:"varint,6,opt,name=click_count,json=clickCount,proto3" json:"click_count,omitempty"`            // human clicks
+	BotClickCount int64                  `protobuf:"varint,7,opt,name=bot_click_count,json=botClickCount,proto3" json:"bot_click_count,omitempty"` // clicks classified as automated
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -217,6 +226,13 @@ func (x *URLResponse) GetClickCount() int64 {
 	return 0
 }
 
+func (x *URLResponse) GetBotClickCount() int64 {
+	if x != nil {
+		return x.BotClickCount
+	}
+	return 0
+}
+
 type ValidateURLRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
@@ -266,6 +282,7 @@ type ValidationResponse struct {
 	IsValid       bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
 	IsSafe        bool                   `protobuf:"varint,2,opt,name=is_safe,json=isSafe,proto3" json:"is_safe,omitempty"`
 	Reason        *string                `protobuf:"bytes,3,opt,name=reason,proto3,oneof" json:"reason,omitempty"`
+	Rule          *string                `protobuf:"bytes,4,opt,name=rule,proto3,oneof" json:"rule,omitempty"` // check that rejected the URL
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -321,26 +338,727 @@ func (x *ValidationResponse) GetReason() string {
 	return ""
 }
 
+func (x *ValidationResponse) GetRule() string {
+	if x != nil && x.Rule != nil {
+		return *x.Rule
+	}
+	return ""
+}
+
+type DeleteURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLRequest) Reset() {
+	*x = DeleteURLRequest{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLRequest) ProtoMessage() {}
+
+func (x *DeleteURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLRequest.ProtoReflect.Descriptor instead.
+func (*DeleteURLRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type DeleteURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLResponse) Reset() {
+	*x = DeleteURLResponse{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLResponse) ProtoMessage() {}
+
+func (x *DeleteURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLResponse.ProtoReflect.Descriptor instead.
+func (*DeleteURLResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{6}
+}
+
+type UpdateURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	ExpiresAt     *int64                 `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3,oneof" json:"expires_at,omitempty"`                                                 // unix seconds, 0 clears the expiry
+	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // replaces the metadata if set
+	OriginalUrl   *string                `protobuf:"bytes,4,opt,name=original_url,json=originalUrl,proto3,oneof" json:"original_url,omitempty"`                                            // retargets the short code
+	IsActive      *bool                  `protobuf:"varint,5,opt,name=is_active,json=isActive,proto3,oneof" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateURLRequest) Reset() {
+	*x = UpdateURLRequest{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateURLRequest) ProtoMessage() {}
+
+func (x *UpdateURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateURLRequest.ProtoReflect.Descriptor instead.
+func (*UpdateURLRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetExpiresAt() int64 {
+	if x != nil && x.ExpiresAt != nil {
+		return *x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UpdateURLRequest) GetOriginalUrl() string {
+	if x != nil && x.OriginalUrl != nil {
+		return *x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+type ListUserURLsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // defaults to 20, at most 100
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token of the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserURLsRequest) Reset() {
+	*x = ListUserURLsRequest{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserURLsRequest) ProtoMessage() {}
+
+func (x *ListUserURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserURLsRequest.ProtoReflect.Descriptor instead.
+func (*ListUserURLsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListUserURLsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListUserURLsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUserURLsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListUserURLsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          []*URLResponse         `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserURLsResponse) Reset() {
+	*x = ListUserURLsResponse{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserURLsResponse) ProtoMessage() {}
+
+func (x *ListUserURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserURLsResponse.ProtoReflect.Descriptor instead.
+func (*ListUserURLsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListUserURLsResponse) GetUrls() []*URLResponse {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *ListUserURLsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ResolveRedirectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserAgent     *string                `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3,oneof" json:"user_agent,omitempty"`
+	IpAddress     *string                `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3,oneof" json:"ip_address,omitempty"`
+	Referrer      *string                `protobuf:"bytes,4,opt,name=referrer,proto3,oneof" json:"referrer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRedirectRequest) Reset() {
+	*x = ResolveRedirectRequest{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRedirectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRedirectRequest) ProtoMessage() {}
+
+func (x *ResolveRedirectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRedirectRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRedirectRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResolveRedirectRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *ResolveRedirectRequest) GetUserAgent() string {
+	if x != nil && x.UserAgent != nil {
+		return *x.UserAgent
+	}
+	return ""
+}
+
+func (x *ResolveRedirectRequest) GetIpAddress() string {
+	if x != nil && x.IpAddress != nil {
+		return *x.IpAddress
+	}
+	return ""
+}
+
+func (x *ResolveRedirectRequest) GetReferrer() string {
+	if x != nil && x.Referrer != nil {
+		return *x.Referrer
+	}
+	return ""
+}
+
+type ResolveRedirectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRedirectResponse) Reset() {
+	*x = ResolveRedirectResponse{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRedirectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRedirectResponse) ProtoMessage() {}
+
+func (x *ResolveRedirectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRedirectResponse.ProtoReflect.Descriptor instead.
+func (*ResolveRedirectResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ResolveRedirectResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+type GetURLStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	Granularity   string                 `protobuf:"bytes,2,opt,name=granularity,proto3" json:"granularity,omitempty"` // hour, day or week; defaults to day
+	From          *int64                 `protobuf:"varint,3,opt,name=from,proto3,oneof" json:"from,omitempty"`        // unix seconds, inclusive
+	To            *int64                 `protobuf:"varint,4,opt,name=to,proto3,oneof" json:"to,omitempty"`            // unix seconds, exclusive; defaults to now
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`            // entries per top list, defaults to 10, at most 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLStatsRequest) Reset() {
+	*x = GetURLStatsRequest{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsRequest) ProtoMessage() {}
+
+func (x *GetURLStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetURLStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetURLStatsRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetURLStatsRequest) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *GetURLStatsRequest) GetFrom() int64 {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return 0
+}
+
+func (x *GetURLStatsRequest) GetTo() int64 {
+	if x != nil && x.To != nil {
+		return *x.To
+	}
+	return 0
+}
+
+func (x *GetURLStatsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type URLStatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode      string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	Granularity    string                 `protobuf:"bytes,2,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	From           int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"` // range aligned to buckets
+	To             int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`
+	TotalClicks    int64                  `protobuf:"varint,5,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	UniqueVisitors int64                  `protobuf:"varint,6,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"` // estimate
+	Series         []*StatsBucket         `protobuf:"bytes,7,rep,name=series,proto3" json:"series,omitempty"`
+	TopReferrers   []*StatsCount          `protobuf:"bytes,8,rep,name=top_referrers,json=topReferrers,proto3" json:"top_referrers,omitempty"`
+	TopCountries   []*StatsCount          `protobuf:"bytes,9,rep,name=top_countries,json=topCountries,proto3" json:"top_countries,omitempty"`
+	TopDevices     []*StatsCount          `protobuf:"bytes,10,rep,name=top_devices,json=topDevices,proto3" json:"top_devices,omitempty"`
+	TopBrowsers    []*StatsCount          `protobuf:"bytes,11,rep,name=top_browsers,json=topBrowsers,proto3" json:"top_browsers,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *URLStatsResponse) Reset() {
+	*x = URLStatsResponse{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLStatsResponse) ProtoMessage() {}
+
+func (x *URLStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLStatsResponse.ProtoReflect.Descriptor instead.
+func (*URLStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *URLStatsResponse) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLStatsResponse) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *URLStatsResponse) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *URLStatsResponse) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *URLStatsResponse) GetTotalClicks() int64 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+func (x *URLStatsResponse) GetUniqueVisitors() int64 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+func (x *URLStatsResponse) GetSeries() []*StatsBucket {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+func (x *URLStatsResponse) GetTopReferrers() []*StatsCount {
+	if x != nil {
+		return x.TopReferrers
+	}
+	return nil
+}
+
+func (x *URLStatsResponse) GetTopCountries() []*StatsCount {
+	if x != nil {
+		return x.TopCountries
+	}
+	return nil
+}
+
+func (x *URLStatsResponse) GetTopDevices() []*StatsCount {
+	if x != nil {
+		return x.TopDevices
+	}
+	return nil
+}
+
+func (x *URLStatsResponse) GetTopBrowsers() []*StatsCount {
+	if x != nil {
+		return x.TopBrowsers
+	}
+	return nil
+}
+
+type StatsBucket struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Start          int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	Clicks         int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	UniqueVisitors int64                  `protobuf:"varint,3,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StatsBucket) Reset() {
+	*x = StatsBucket{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsBucket) ProtoMessage() {}
+
+func (x *StatsBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsBucket.ProtoReflect.Descriptor instead.
+func (*StatsBucket) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StatsBucket) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *StatsBucket) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+func (x *StatsBucket) GetUniqueVisitors() int64 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+type StatsCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsCount) Reset() {
+	*x = StatsCount{}
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsCount) ProtoMessage() {}
+
+func (x *StatsCount) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_url_v1_url_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsCount.ProtoReflect.Descriptor instead.
+func (*StatsCount) Descriptor() ([]byte, []int) {
+	return file_api_proto_url_v1_url_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StatsCount) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *StatsCount) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
 var File_api_proto_url_v1_url_proto protoreflect.FileDescriptor
 
 const file_api_proto_url_v1_url_proto_rawDesc = "" +
 	"\n" +
-	"\x1aapi/proto/url/v1/url.proto\x12\x06url.v1\"\x82\x02\n" +
+	"\x1aapi/proto/url/v1/url.proto\x12\x06url.v1\"\xbb\x02\n" +
 	"\x10CreateURLRequest\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1c\n" +
 	"\auser_id\x18\x02 \x01(\x03H\x00R\x06userId\x88\x01\x01\x12\"\n" +
 	"\n" +
 	"expires_in\x18\x03 \x01(\x03H\x01R\texpiresIn\x88\x01\x01\x12B\n" +
-	"\bmetadata\x18\x04 \x03(\v2&.url.v1.CreateURLRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x04 \x03(\v2&.url.v1.CreateURLRequest.MetadataEntryR\bmetadata\x12&\n" +
+	"\fcustom_alias\x18\x05 \x01(\tH\x02R\vcustomAlias\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\n" +
 	"\n" +
 	"\b_user_idB\r\n" +
-	"\v_expires_in\".\n" +
+	"\v_expires_inB\x0f\n" +
+	"\r_custom_alias\".\n" +
 	"\rGetURLRequest\x12\x1d\n" +
 	"\n" +
-	"short_code\x18\x01 \x01(\tR\tshortCode\"\xdf\x01\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x87\x02\n" +
 	"\vURLResponse\x12\x1d\n" +
 	"\n" +
 	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1b\n" +
@@ -351,20 +1069,100 @@ const file_api_proto_url_v1_url_proto_rawDesc = "" +
 	"\n" +
 	"expires_at\x18\x05 \x01(\x03H\x00R\texpiresAt\x88\x01\x01\x12\x1f\n" +
 	"\vclick_count\x18\x06 \x01(\x03R\n" +
-	"clickCountB\r\n" +
+	"clickCount\x12&\n" +
+	"\x0fbot_click_count\x18\a \x01(\x03R\rbotClickCountB\r\n" +
 	"\v_expires_at\"&\n" +
 	"\x12ValidateURLRequest\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\"p\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\"\x92\x01\n" +
 	"\x12ValidationResponse\x12\x19\n" +
 	"\bis_valid\x18\x01 \x01(\bR\aisValid\x12\x17\n" +
 	"\ais_safe\x18\x02 \x01(\bR\x06isSafe\x12\x1b\n" +
-	"\x06reason\x18\x03 \x01(\tH\x00R\x06reason\x88\x01\x01B\t\n" +
-	"\a_reason2\xc5\x01\n" +
+	"\x06reason\x18\x03 \x01(\tH\x00R\x06reason\x88\x01\x01\x12\x17\n" +
+	"\x04rule\x18\x04 \x01(\tH\x01R\x04rule\x88\x01\x01B\t\n" +
+	"\a_reasonB\a\n" +
+	"\x05_rule\"1\n" +
+	"\x10DeleteURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x13\n" +
+	"\x11DeleteURLResponse\"\xce\x02\n" +
+	"\x10UpdateURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\"\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03H\x00R\texpiresAt\x88\x01\x01\x12B\n" +
+	"\bmetadata\x18\x03 \x03(\v2&.url.v1.UpdateURLRequest.MetadataEntryR\bmetadata\x12&\n" +
+	"\foriginal_url\x18\x04 \x01(\tH\x01R\voriginalUrl\x88\x01\x01\x12 \n" +
+	"\tis_active\x18\x05 \x01(\bH\x02R\bisActive\x88\x01\x01\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\r\n" +
+	"\v_expires_atB\x0f\n" +
+	"\r_original_urlB\f\n" +
+	"\n" +
+	"_is_active\"j\n" +
+	"\x13ListUserURLsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"g\n" +
+	"\x14ListUserURLsResponse\x12'\n" +
+	"\x04urls\x18\x01 \x03(\v2\x13.url.v1.URLResponseR\x04urls\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xcb\x01\n" +
+	"\x16ResolveRedirectRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\"\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tH\x00R\tuserAgent\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tH\x01R\tipAddress\x88\x01\x01\x12\x1f\n" +
+	"\breferrer\x18\x04 \x01(\tH\x02R\breferrer\x88\x01\x01B\r\n" +
+	"\v_user_agentB\r\n" +
+	"\v_ip_addressB\v\n" +
+	"\t_referrer\"<\n" +
+	"\x17ResolveRedirectResponse\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\"\xa9\x01\n" +
+	"\x12GetURLStatsRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12 \n" +
+	"\vgranularity\x18\x02 \x01(\tR\vgranularity\x12\x17\n" +
+	"\x04from\x18\x03 \x01(\x03H\x00R\x04from\x88\x01\x01\x12\x13\n" +
+	"\x02to\x18\x04 \x01(\x03H\x01R\x02to\x88\x01\x01\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limitB\a\n" +
+	"\x05_fromB\x05\n" +
+	"\x03_to\"\xce\x03\n" +
+	"\x10URLStatsResponse\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12 \n" +
+	"\vgranularity\x18\x02 \x01(\tR\vgranularity\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\x12!\n" +
+	"\ftotal_clicks\x18\x05 \x01(\x03R\vtotalClicks\x12'\n" +
+	"\x0funique_visitors\x18\x06 \x01(\x03R\x0euniqueVisitors\x12+\n" +
+	"\x06series\x18\a \x03(\v2\x13.url.v1.StatsBucketR\x06series\x127\n" +
+	"\rtop_referrers\x18\b \x03(\v2\x12.url.v1.StatsCountR\ftopReferrers\x127\n" +
+	"\rtop_countries\x18\t \x03(\v2\x12.url.v1.StatsCountR\ftopCountries\x123\n" +
+	"\vtop_devices\x18\n" +
+	" \x03(\v2\x12.url.v1.StatsCountR\n" +
+	"topDevices\x125\n" +
+	"\ftop_browsers\x18\v \x03(\v2\x12.url.v1.StatsCountR\vtopBrowsers\"d\n" +
+	"\vStatsBucket\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x03R\x05start\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\x12'\n" +
+	"\x0funique_visitors\x18\x03 \x01(\x03R\x0euniqueVisitors\":\n" +
+	"\n" +
+	"StatsCount\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks2\xa7\x04\n" +
 	"\n" +
 	"URLService\x12:\n" +
 	"\tCreateURL\x12\x18.url.v1.CreateURLRequest\x1a\x13.url.v1.URLResponse\x124\n" +
 	"\x06GetURL\x12\x15.url.v1.GetURLRequest\x1a\x13.url.v1.URLResponse\x12E\n" +
-	"\vValidateURL\x12\x1a.url.v1.ValidateURLRequest\x1a\x1a.url.v1.ValidationResponseB&Z$url-shortener/api/proto/url/v1;urlpbb\x06proto3"
+	"\vValidateURL\x12\x1a.url.v1.ValidateURLRequest\x1a\x1a.url.v1.ValidationResponse\x12@\n" +
+	"\tDeleteURL\x12\x18.url.v1.DeleteURLRequest\x1a\x19.url.v1.DeleteURLResponse\x12:\n" +
+	"\tUpdateURL\x12\x18.url.v1.UpdateURLRequest\x1a\x13.url.v1.URLResponse\x12I\n" +
+	"\fListUserURLs\x12\x1b.url.v1.ListUserURLsRequest\x1a\x1c.url.v1.ListUserURLsResponse\x12R\n" +
+	"\x0fResolveRedirect\x12\x1e.url.v1.ResolveRedirectRequest\x1a\x1f.url.v1.ResolveRedirectResponse\x12C\n" +
+	"\vGetURLStats\x12\x1a.url.v1.GetURLStatsRequest\x1a\x18.url.v1.URLStatsResponseB&Z$url-shortener/api/proto/url/v1;urlpbb\x06proto3"
 
 var (
 	file_api_proto_url_v1_url_proto_rawDescOnce sync.Once
@@ -378,28 +1176,57 @@ func file_api_proto_url_v1_url_proto_rawDescGZIP() []byte {
 	return file_api_proto_url_v1_url_proto_rawDescData
 }
 
-var file_api_proto_url_v1_url_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_proto_url_v1_url_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_api_proto_url_v1_url_proto_goTypes = []any{
-	(*CreateURLRequest)(nil),   // 0: url.v1.CreateURLRequest
-	(*GetURLRequest)(nil),      // 1: url.v1.GetURLRequest
-	(*URLResponse)(nil),        // 2: url.v1.URLResponse
-	(*ValidateURLRequest)(nil), // 3: url.v1.ValidateURLRequest
-	(*ValidationResponse)(nil), // 4: url.v1.ValidationResponse
-	nil,                        // 5: url.v1.CreateURLRequest.MetadataEntry
+	(*CreateURLRequest)(nil),        // 0: url.v1.CreateURLRequest
+	(*GetURLRequest)(nil),           // 1: url.v1.GetURLRequest
+	(*URLResponse)(nil),             // 2: url.v1.URLResponse
+	(*ValidateURLRequest)(nil),      // 3: url.v1.ValidateURLRequest
+	(*ValidationResponse)(nil),      // 4: url.v1.ValidationResponse
+	(*DeleteURLRequest)(nil),        // 5: url.v1.DeleteURLRequest
+	(*DeleteURLResponse)(nil),       // 6: url.v1.DeleteURLResponse
+	(*UpdateURLRequest)(nil),        // 7: url.v1.UpdateURLRequest
+	(*ListUserURLsRequest)(nil),     // 8: url.v1.ListUserURLsRequest
+	(*ListUserURLsResponse)(nil),    // 9: url.v1.ListUserURLsResponse
+	(*ResolveRedirectRequest)(nil),  // 10: url.v1.ResolveRedirectRequest
+	(*ResolveRedirectResponse)(nil), // 11: url.v1.ResolveRedirectResponse
+	(*GetURLStatsRequest)(nil),      // 12: url.v1.GetURLStatsRequest
+	(*URLStatsResponse)(nil),        // 13: url.v1.URLStatsResponse
+	(*StatsBucket)(nil),             // 14: url.v1.StatsBucket
+	(*StatsCount)(nil),              // 15: url.v1.StatsCount
+	nil,                             // 16: url.v1.CreateURLRequest.MetadataEntry
+	nil,                             // 17: url.v1.UpdateURLRequest.MetadataEntry
 }
 var file_api_proto_url_v1_url_proto_depIdxs = []int32{
-	5, // 0: url.v1.CreateURLRequest.metadata:type_name -> url.v1.CreateURLRequest.MetadataEntry
-	0, // 1: url.v1.URLService.CreateURL:input_type -> url.v1.CreateURLRequest
-	1, // 2: url.v1.URLService.GetURL:input_type -> url.v1.GetURLRequest
-	3, // 3: url.v1.URLService.ValidateURL:input_type -> url.v1.ValidateURLRequest
-	2, // 4: url.v1.URLService.CreateURL:output_type -> url.v1.URLResponse
-	2, // 5: url.v1.URLService.GetURL:output_type -> url.v1.URLResponse
-	4, // 6: url.v1.URLService.ValidateURL:output_type -> url.v1.ValidationResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	16, // 0: url.v1.CreateURLRequest.metadata:type_name -> url.v1.CreateURLRequest.MetadataEntry
+	17, // 1: url.v1.UpdateURLRequest.metadata:type_name -> url.v1.UpdateURLRequest.MetadataEntry
+	2,  // 2: url.v1.ListUserURLsResponse.urls:type_name -> url.v1.URLResponse
+	14, // 3: url.v1.URLStatsResponse.series:type_name -> url.v1.StatsBucket
+	15, // 4: url.v1.URLStatsResponse.top_referrers:type_name -> url.v1.StatsCount
+	15, // 5: url.v1.URLStatsResponse.top_countries:type_name -> url.v1.StatsCount
+	15, // 6: url.v1.URLStatsResponse.top_devices:type_name -> url.v1.StatsCount
+	15, // 7: url.v1.URLStatsResponse.top_browsers:type_name -> url.v1.StatsCount
+	0,  // 8: url.v1.URLService.CreateURL:input_type -> url.v1.CreateURLRequest
+	1,  // 9: url.v1.URLService.GetURL:input_type -> url.v1.GetURLRequest
+	3,  // 10: url.v1.URLService.ValidateURL:input_type -> url.v1.ValidateURLRequest
+	5,  // 11: url.v1.URLService.DeleteURL:input_type -> url.v1.DeleteURLRequest
+	7,  // 12: url.v1.URLService.UpdateURL:input_type -> url.v1.UpdateURLRequest
+	8,  // 13: url.v1.URLService.ListUserURLs:input_type -> url.v1.ListUserURLsRequest
+	10, // 14: url.v1.URLService.ResolveRedirect:input_type -> url.v1.ResolveRedirectRequest
+	12, // 15: url.v1.URLService.GetURLStats:input_type -> url.v1.GetURLStatsRequest
+	2,  // 16: url.v1.URLService.CreateURL:output_type -> url.v1.URLResponse
+	2,  // 17: url.v1.URLService.GetURL:output_type -> url.v1.URLResponse
+	4,  // 18: url.v1.URLService.ValidateURL:output_type -> url.v1.ValidationResponse
+	6,  // 19: url.v1.URLService.DeleteURL:output_type -> url.v1.DeleteURLResponse
+	2,  // 20: url.v1.URLService.UpdateURL:output_type -> url.v1.URLResponse
+	9,  // 21: url.v1.URLService.ListUserURLs:output_type -> url.v1.ListUserURLsResponse
+	11, // 22: url.v1.URLService.ResolveRedirect:output_type -> url.v1.ResolveRedirectResponse
+	13, // 23: url.v1.URLService.GetURLStats:output_type -> url.v1.URLStatsResponse
+	16, // [16:24] is the sub-list for method output_type
+	8,  // [8:16] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_url_v1_url_proto_init() }
@@ -410,13 +1237,16 @@ func file_api_proto_url_v1_url_proto_init() {
 	file_api_proto_url_v1_url_proto_msgTypes[0].OneofWrappers = []any{}
 	file_api_proto_url_v1_url_proto_msgTypes[2].OneofWrappers = []any{}
 	file_api_proto_url_v1_url_proto_msgTypes[4].OneofWrappers = []any{}
+	file_api_proto_url_v1_url_proto_msgTypes[7].OneofWrappers = []any{}
+	file_api_proto_url_v1_url_proto_msgTypes[10].OneofWrappers = []any{}
+	file_api_proto_url_v1_url_proto_msgTypes[12].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_url_v1_url_proto_rawDesc), len(file_api_proto_url_v1_url_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	URLService_CreateURL_FullMethodName   = "/url.v1.URLService/CreateURL"
-	URLService_GetURL_FullMethodName      = "/url.v1.URLService/GetURL"
-	URLService_ValidateURL_FullMethodName = "/url.v1.URLService/ValidateURL"
+	URLService_CreateURL_FullMethodName       = "/url.v1.URLService/CreateURL"
+	URLService_GetURL_FullMethodName          = "/url.v1.URLService/GetURL"
+	URLService_ValidateURL_FullMethodName     = "/url.v1.URLService/ValidateURL"
+	URLService_DeleteURL_FullMethodName       = "/url.v1.URLService/DeleteURL"
+	URLService_UpdateURL_FullMethodName       = "/url.v1.URLService/UpdateURL"
+	URLService_ListUserURLs_FullMethodName    = "/url.v1.URLService/ListUserURLs"
+	URLService_ResolveRedirect_FullMethodName = "/url.v1.URLService/ResolveRedirect"
+	URLService_GetURLStats_FullMethodName     = "/url.v1.URLService/GetURLStats"
 )
 
 // URLServiceClient is the client API for URLService service.
@@ -31,6 +36,14 @@ type URLServiceClient interface {
 	CreateURL(ctx context.Context, in *CreateURLRequest, opts ...grpc.CallOption) (*URLResponse, error)
 	GetURL(ctx context.Context, in *GetURLRequest, opts ...grpc.CallOption) (*URLResponse, error)
 	ValidateURL(ctx context.Context, in *ValidateURLRequest, opts ...grpc.CallOption) (*ValidationResponse, error)
+	DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*DeleteURLResponse, error)
+	UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*URLResponse, error)
+	ListUserURLs(ctx context.Context, in *ListUserURLsRequest, opts ...grpc.CallOption) (*ListUserURLsResponse, error)
+	// ResolveRedirect returns the destination of a short code and records the
+	// click, as the HTTP redirect endpoint does
+	ResolveRedirect(ctx context.Context, in *ResolveRedirectRequest, opts ...grpc.CallOption) (*ResolveRedirectResponse, error)
+	// GetURLStats returns click statistics aggregated by the click-consumer
+	GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*URLStatsResponse, error)
 }
 
 type uRLServiceClient struct {
@@ -71,6 +84,56 @@ func (c *uRLServiceClient) ValidateURL(ctx context.Context, in *ValidateURLReque
 	return out, nil
 }
 
+func (c *uRLServiceClient) DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*DeleteURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteURLResponse)
+	err := c.cc.Invoke(ctx, URLService_DeleteURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*URLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(URLResponse)
+	err := c.cc.Invoke(ctx, URLService_UpdateURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) ListUserURLs(ctx context.Context, in *ListUserURLsRequest, opts ...grpc.CallOption) (*ListUserURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUserURLsResponse)
+	err := c.cc.Invoke(ctx, URLService_ListUserURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) ResolveRedirect(ctx context.Context, in *ResolveRedirectRequest, opts ...grpc.CallOption) (*ResolveRedirectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveRedirectResponse)
+	err := c.cc.Invoke(ctx, URLService_ResolveRedirect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*URLStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(URLStatsResponse)
+	err := c.cc.Invoke(ctx, URLService_GetURLStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // URLServiceServer is the server API for URLService service.
 // All implementations must embed UnimplementedURLServiceServer
 // for forward compatibility.
@@ -78,6 +141,14 @@ type URLServiceServer interface {
 	CreateURL(context.Context, *CreateURLRequest) (*URLResponse, error)
 	GetURL(context.Context, *GetURLRequest) (*URLResponse, error)
 	ValidateURL(context.Context, *ValidateURLRequest) (*ValidationResponse, error)
+	DeleteURL(context.Context, *DeleteURLRequest) (*DeleteURLResponse, error)
+	UpdateURL(context.Context, *UpdateURLRequest) (*URLResponse, error)
+	ListUserURLs(context.Context, *ListUserURLsRequest) (*ListUserURLsResponse, error)
+	// ResolveRedirect returns the destination of a short code and records the
+	// click, as the HTTP redirect endpoint does
+	ResolveRedirect(context.Context, *ResolveRedirectRequest) (*ResolveRedirectResponse, error)
+	// GetURLStats returns click statistics aggregated by the click-consumer
+	GetURLStats(context.Context, *GetURLStatsRequest) (*URLStatsResponse, error)
 	mustEmbedUnimplementedURLServiceServer()
 }
 
@@ -97,6 +168,21 @@ func (UnimplementedURLServiceServer) GetURL(context.Context, *GetURLRequest) (*U
 func (UnimplementedURLServiceServer) ValidateURL(context.Context, *ValidateURLRequest) (*ValidationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateURL not implemented")
 }
+func (UnimplementedURLServiceServer) DeleteURL(context.Context, *DeleteURLRequest) (*DeleteURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteURL not implemented")
+}
+func (UnimplementedURLServiceServer) UpdateURL(context.Context, *UpdateURLRequest) (*URLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
+func (UnimplementedURLServiceServer) ListUserURLs(context.Context, *ListUserURLsRequest) (*ListUserURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserURLs not implemented")
+}
+func (UnimplementedURLServiceServer) ResolveRedirect(context.Context, *ResolveRedirectRequest) (*ResolveRedirectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveRedirect not implemented")
+}
+func (UnimplementedURLServiceServer) GetURLStats(context.Context, *GetURLStatsRequest) (*URLStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLStats not implemented")
+}
 func (UnimplementedURLServiceServer) mustEmbedUnimplementedURLServiceServer() {}
 func (UnimplementedURLServiceServer) testEmbeddedByValue()                    {}
 
@@ -172,6 +258,96 @@ func _URLService_ValidateURL_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLService_DeleteURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).DeleteURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_DeleteURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).DeleteURL(ctx, req.(*DeleteURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).UpdateURL(ctx, req.(*UpdateURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_ListUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).ListUserURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_ListUserURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).ListUserURLs(ctx, req.(*ListUserURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_ResolveRedirect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRedirectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).ResolveRedirect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_ResolveRedirect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).ResolveRedirect(ctx, req.(*ResolveRedirectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_GetURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).GetURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_GetURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).GetURLStats(ctx, req.(*GetURLStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // URLService_ServiceDesc is the grpc.ServiceDesc for URLService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +367,26 @@ var URLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateURL",
 			Handler:    _URLService_ValidateURL_Handler,
 		},
+		{
+			MethodName: "DeleteURL",
+			Handler:    _URLService_DeleteURL_Handler,
+		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _URLService_UpdateURL_Handler,
+		},
+		{
+			MethodName: "ListUserURLs",
+			Handler:    _URLService_ListUserURLs_Handler,
+		},
+		{
+			MethodName: "ResolveRedirect",
+			Handler:    _URLService_ResolveRedirect_Handler,
+		},
+		{
+			MethodName: "GetURLStats",
+			Handler:    _URLService_GetURLStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/url/v1/url.proto",
@@ -0,0 +1,174 @@
+// Command backfill-canonical fills urls.canonical_url for rows created before
+// URLs were canonicalized, using the same normalizer settings as the URL
+// service. Run it after adding the canonical_url column and before creating
+// idx_urls_user_canonical_url_not_deleted; it is safe to re-run.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/config"
+	"github.com/umanagarjuna/go-url-shortener/pkg/urlnorm"
+)
+
+const batchSize = 500
+
+type urlRow struct {
+	ID          int64  `db:"id"`
+	OriginalURL string `db:"original_url"`
+}
+
+func main() {
+	// Initialize logger
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	// Initialize database
+	db, err := initDB(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	normalizer := urlnorm.New(urlnorm.Options{
+		SortQuery:           cfg.Service.Canonicalization.SortQueryParams,
+		StripTrackingParams: cfg.Service.Canonicalization.StripTrackingParams,
+		TrackingParams:      cfg.Service.Canonicalization.TrackingParams,
+	})
+
+	updated, kept, err := backfill(ctx, db, normalizer, logger)
+	if err != nil {
+		logger.Error("Canonical URL backfill failed",
+			zap.Int64("updated", updated), zap.Int64("kept_original", kept), zap.Error(err))
+		os.Exit(1)
+	}
+
+	logger.Info("Canonical URL backfill complete",
+		zap.Int64("updated", updated), zap.Int64("kept_original", kept))
+}
+
+// backfill walks the rows that were never canonicalized, in id order. Rows
+// copied verbatim by the earlier SQL backfill (canonical_url = original_url)
+// are included; normalizing is idempotent, so re-running only rewrites rows
+// whose canonical form differs.
+//
+// When a live row's canonical form is already taken by another live row of
+// the same user, the row keeps its original URL as canonical_url so the
+// per-user unique index can still be built. Those rows are counted as kept.
+func backfill(ctx context.Context, db *sqlx.DB, normalizer *urlnorm.Normalizer,
+	logger *zap.Logger) (updated, kept int64, err error) {
+
+	var lastID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return updated, kept, err
+		}
+
+		var rows []urlRow
+		err := db.SelectContext(ctx, &rows, `
+            SELECT id, original_url
+            FROM urls
+            WHERE id > $1
+              AND (canonical_url IS NULL OR canonical_url = original_url)
+            ORDER BY id
+            LIMIT $2`, lastID, batchSize)
+		if err != nil {
+			return updated, kept, fmt.Errorf("failed to list URLs after id %d: %w", lastID, err)
+		}
+		if len(rows) == 0 {
+			return updated, kept, nil
+		}
+
+		for _, row := range rows {
+			lastID = row.ID
+
+			canonicalURL, err := normalizer.Normalize(row.OriginalURL)
+			if err != nil {
+				// The service falls back to the raw URL for unparsable input too
+				canonicalURL = row.OriginalURL
+			}
+
+			applied, err := setCanonicalURL(ctx, db, row.ID, canonicalURL)
+			if err != nil {
+				return updated, kept, err
+			}
+			if applied {
+				if canonicalURL != row.OriginalURL {
+					updated++
+				}
+				continue
+			}
+
+			kept++
+			logger.Warn("Canonical URL already used by another live URL of the user, keeping original",
+				zap.Int64("id", row.ID), zap.String("canonical_url", canonicalURL))
+		}
+	}
+}
+
+// setCanonicalURL sets the canonical URL of a row unless another live row of
+// the same user already has it, in which case the row gets its original URL.
+// It reports whether canonicalURL was applied.
+func setCanonicalURL(ctx context.Context, db *sqlx.DB, id int64,
+	canonicalURL string) (bool, error) {
+
+	var applied bool
+	err := db.GetContext(ctx, &applied, `
+        UPDATE urls u
+        SET canonical_url = CASE
+                WHEN u.deleted_at IS NOT NULL OR NOT EXISTS (
+                    SELECT 1 FROM urls o
+                    WHERE o.user_id = u.user_id
+                      AND o.canonical_url = $2
+                      AND o.deleted_at IS NULL
+                      AND o.id <> u.id)
+                THEN $2
+                ELSE u.original_url
+            END
+        WHERE u.id = $1
+        RETURNING u.canonical_url = $2`, id, canonicalURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil // purged meanwhile
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to set canonical URL of id %d: %w", id, err)
+	}
+
+	return applied, nil
+}
+
+func initDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(2)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	return db, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/analytics"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/config"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+func main() {
+	// Initialize logger
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
+	defer logger.Sync()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	// Initialize database
+	db, err := initDB(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	groupID := cfg.Analytics.ConsumerGroup
+	if groupID == "" {
+		groupID = "click-analytics"
+	}
+	source, err := analytics.NewKafkaSource(logger, analytics.KafkaSourceConfig{
+		Brokers:      cfg.Kafka.Brokers,
+		Topic:        events.TopicURLClicked,
+		GroupID:      groupID,
+		RetryBackoff: cfg.Analytics.RetryBackoff,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize Kafka consumer", zap.Error(err))
+	}
+	defer source.Close()
+
+	store := analytics.NewPostgresStore(db)
+	processor := analytics.NewProcessor(store, metrics.NewInMemoryMetrics(), logger)
+
+	go pruneProcessed(ctx, store, cfg.Analytics.DedupeRetention, logger)
+
+	errChan := make(chan error, 1)
+	go func() {
+		logger.Info("Starting click consumer",
+			zap.String("topic", events.TopicURLClicked),
+			zap.String("group", groupID))
+		errChan <- source.Consume(ctx, processor.Handle)
+	}()
+
+	// Wait for shutdown signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			logger.Fatal("Click consumer error", zap.Error(err))
+		}
+	case sig := <-sigChan:
+		logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	}
+
+	cancel()
+	logger.Info("Click consumer stopped")
+}
+
+// pruneProcessed periodically forgets processed event IDs older than
+// retention
+func pruneProcessed(ctx context.Context, store *analytics.PostgresStore,
+	retention time.Duration, logger *zap.Logger) {
+
+	if retention <= 0 {
+		retention = 7 * 24 * time.Hour
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := store.PruneProcessed(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.Error("Failed to prune processed click events", zap.Error(err))
+				continue
+			}
+			logger.Debug("Pruned processed click events", zap.Int64("count", pruned))
+		}
+	}
+}
+
+func initDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	return db, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -18,12 +19,20 @@ import (
 	pb "github.com/umanagarjuna/go-url-shortener/api/proto/url/v1"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/cache"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/config"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/enrich"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/handler"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/keypool"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/outbox"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/repository"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/service"
+	"github.com/umanagarjuna/go-url-shortener/pkg/botdetect"
+	"github.com/umanagarjuna/go-url-shortener/pkg/geoip"
 	"github.com/umanagarjuna/go-url-shortener/pkg/shortcode"
+	"github.com/umanagarjuna/go-url-shortener/pkg/urlnorm"
+	"github.com/umanagarjuna/go-url-shortener/pkg/useragent"
 	"github.com/umanagarjuna/go-url-shortener/pkg/validator"
 )
 
@@ -35,6 +44,9 @@ func main() {
 	}
 	defer logger.Sync()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -52,22 +64,74 @@ func main() {
 	redisClient := initRedis(cfg.Redis)
 	defer redisClient.Close()
 
-	// Initialize Kafka publisher
-	publisher, err := events.NewEventPublisher(cfg.Kafka.Brokers)
+	// Initialize Kafka producer
+	kafkaSender, err := events.NewKafkaSender(cfg.Kafka.Brokers)
 	if err != nil {
-		logger.Fatal("Failed to initialize event publisher", zap.Error(err))
+		logger.Fatal("Failed to initialize Kafka producer", zap.Error(err))
 	}
-	defer publisher.Close()
+	defer kafkaSender.Close()
 
-	// Initialize dependencies
-	repo := repository.NewPostgresRepository(db)
-	cacheLayer := cache.NewRedisCache(redisClient)
-	generator := shortcode.NewBase62GeneratorWithLength(10)
-	urlValidator := validator.NewDefaultValidator()
+	eventsConfig, err := newEventsConfig(cfg.Kafka)
+	if err != nil {
+		logger.Fatal("Invalid Kafka configuration", zap.Error(err))
+	}
+
+	// Domain events go through the transactional outbox; clicks are too
+	// frequent for it and go straight to Kafka
+	publisher := events.NewEventPublisherWithSender(&events.TopicRouter{
+		Default: outbox.NewWriter(db),
+		Topics:  map[string]events.Sender{events.TopicURLClicked: kafkaSender},
+	}, eventsConfig)
 
 	// Initialize metrics
 	metricsCollector := metrics.NewInMemoryMetrics()
 
+	outbox.NewRelay(outbox.NewPostgresStore(db), kafkaSender, metricsCollector, logger, outbox.Config{
+		BatchSize:    cfg.Outbox.BatchSize,
+		PollInterval: cfg.Outbox.PollInterval,
+		MaxBackoff:   cfg.Outbox.MaxBackoff,
+		Retention:    cfg.Outbox.Retention,
+	}).Start(ctx)
+
+	// Initialize dependencies
+	generator, err := newShortCodeGenerator(ctx, cfg, db, metricsCollector, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize short code generator", zap.Error(err))
+	}
+	idEncoder, _ := shortcode.Unwrap(generator).(shortcode.IDEncoder)
+	codeChecker, _ := generator.(shortcode.CodeChecker)
+	repo := repository.NewPostgresRepository(db, repository.Config{
+		IDEncoder:   idEncoder,
+		CodeChecker: codeChecker,
+		Publisher:   publisher,
+	})
+	cacheLayer := cache.NewRedisCache(redisClient)
+	threatLists, err := initThreatLists(ctx, cfg.Validator.ThreatLists, logger)
+	if err != nil {
+		logger.Fatal("Failed to load threat lists", zap.Error(err))
+	}
+	domainPolicy, err := initDomainPolicy(ctx, cfg.Validator.DomainPolicy, logger)
+	if err != nil {
+		logger.Fatal("Failed to load domain policy", zap.Error(err))
+	}
+	urlValidator := validator.NewValidator(validator.Config{
+		ResolveTimeout:       cfg.Validator.ResolveTimeout,
+		AllowPrivateNetworks: cfg.Validator.AllowPrivateNetworks,
+		ThreatLists:          threatLists,
+		DomainPolicy:         domainPolicy,
+		FollowRedirects:      cfg.Validator.FollowRedirects,
+		MaxRedirects:         cfg.Validator.MaxRedirects,
+		RedirectTimeout:      cfg.Validator.RedirectTimeout,
+		SelfURL:              cfg.Service.BaseURL,
+		ProtectedDomains:     cfg.Validator.ProtectedDomains,
+		MaxSubdomainDepth:    cfg.Validator.MaxSubdomainDepth,
+	})
+
+	clickEnrichers, err := initClickEnrichers(ctx, cfg.Enrichment, metricsCollector, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize click enrichment", zap.Error(err))
+	}
+
 	// Initialize service
 	urlService := service.NewURLService(
 		repo,
@@ -78,10 +142,28 @@ func main() {
 		logger,
 		metricsCollector, // NEW
 		service.Config{
-			BaseURL: cfg.Service.BaseURL,
+			BaseURL:        cfg.Service.BaseURL,
+			RiskPolicy:     cfg.Service.RiskPolicy,
+			RiskThreshold:  cfg.Service.RiskThreshold,
+			PurgeRetention: cfg.Purge.Retention,
+			Normalizer: urlnorm.New(urlnorm.Options{
+				SortQuery:           cfg.Service.Canonicalization.SortQueryParams,
+				StripTrackingParams: cfg.Service.Canonicalization.StripTrackingParams,
+				TrackingParams:      cfg.Service.Canonicalization.TrackingParams,
+			}),
+			Stats:          repository.NewPostgresStatsRepository(db),
+			ClickEnrichers: clickEnrichers,
 		},
 	)
 
+	if cfg.Purge.Enabled {
+		interval := cfg.Purge.Interval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		go urlService.RunPurgeJob(ctx, interval)
+	}
+
 	// Start servers
 	errChan := make(chan error, 2)
 
@@ -111,7 +193,10 @@ func main() {
 			return
 		}
 
-		grpcServer := grpc.NewServer()
+		grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+			handler.ActorUnaryInterceptor,
+			handler.TraceUnaryInterceptor,
+		))
 		pb.RegisterURLServiceServer(grpcServer, grpcHandler)
 
 		logger.Info("Starting gRPC server", zap.String("port", cfg.Server.GRPCPort))
@@ -147,6 +232,227 @@ func initDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 	return db, nil
 }
 
+func newShortCodeGenerator(ctx context.Context, cfg *config.Config, db *sqlx.DB,
+	metricsCollector metrics.Metrics, logger *zap.Logger) (shortcode.Generator, error) {
+
+	generator, err := newBaseShortCodeGenerator(ctx, cfg, db, metricsCollector, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter offensive codes (and custom aliases) when a denylist is configured
+	if cfg.Service.DenylistFile != "" {
+		denylist, err := shortcode.LoadDenylist(cfg.Service.DenylistFile)
+		if err != nil {
+			return nil, err
+		}
+		generator = shortcode.NewFilteredGenerator(generator, denylist)
+	}
+
+	return generator, nil
+}
+
+func newBaseShortCodeGenerator(ctx context.Context, cfg *config.Config, db *sqlx.DB,
+	metricsCollector metrics.Metrics, logger *zap.Logger) (shortcode.Generator, error) {
+
+	length := cfg.Service.ShortCodeLength
+	if length == 0 {
+		length = 10
+	}
+
+	var charset string
+	switch cfg.Service.Charset {
+	case "", "base62":
+		charset = shortcode.DefaultCharset
+	case "unambiguous":
+		charset = shortcode.UnambiguousCharset
+	default:
+		return nil, fmt.Errorf("unknown short code charset %q", cfg.Service.Charset)
+	}
+
+	switch cfg.Service.Generator {
+	case "", "base62":
+		return shortcode.NewBase62GeneratorWithCharset(length, charset), nil
+	case "snowflake":
+		return shortcode.NewSnowflakeGeneratorWithCharset(cfg.Service.MachineID, charset)
+	case "pool":
+		pool := keypool.NewPool(
+			keypool.NewPostgresStore(db),
+			shortcode.NewBase62GeneratorWithCharset(length, charset),
+			metricsCollector,
+			logger,
+			keypool.Config{
+				Size:           cfg.KeyPool.Size,
+				RefillBatch:    cfg.KeyPool.RefillBatch,
+				LowWatermark:   cfg.KeyPool.LowWatermark,
+				RefillInterval: cfg.KeyPool.RefillInterval,
+			},
+		)
+		pool.Start(ctx)
+		return pool, nil
+	case "hashids":
+		// The alphabet is part of the encoding: changing it would remap every
+		// existing code, so hashids always uses the default one
+		if charset != shortcode.DefaultCharset {
+			return nil, fmt.Errorf("charset %q is not supported by the hashids generator", cfg.Service.Charset)
+		}
+		return shortcode.NewHashidsGenerator(cfg.Service.HashidsSalt, cfg.Service.HashidsMinLength)
+	default:
+		return nil, fmt.Errorf("unknown short code generator %q", cfg.Service.Generator)
+	}
+}
+
+// initThreatLists loads the configured threat feeds and keeps them fresh.
+// It returns nil when no feeds are configured.
+func initThreatLists(ctx context.Context, cfg config.ThreatListConfig,
+	logger *zap.Logger) (*validator.ThreatLists, error) {
+
+	if len(cfg.HashPrefixFiles) == 0 && len(cfg.DomainFiles) == 0 && len(cfg.URLFiles) == 0 {
+		return nil, nil
+	}
+
+	listConfig := validator.ThreatListConfig{
+		HashPrefixFiles: cfg.HashPrefixFiles,
+		DomainFiles:     cfg.DomainFiles,
+		URLFiles:        cfg.URLFiles,
+		RefreshInterval: cfg.RefreshInterval,
+	}
+	if cfg.SafeBrowsingAPIKey != "" {
+		listConfig.FullHashes = validator.NewSafeBrowsingFullHashes(validator.SafeBrowsingConfig{
+			APIKey: cfg.SafeBrowsingAPIKey,
+		})
+	} else if len(cfg.HashPrefixFiles) > 0 {
+		logger.Warn("No Safe Browsing API key configured, hash prefix hits are reported without full hash confirmation",
+			zap.Strings("files", cfg.HashPrefixFiles))
+	}
+
+	threatLists, err := validator.NewThreatLists(listConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	go threatLists.Watch(ctx, func(err error) {
+		logger.Error("Failed to reload threat lists", zap.Error(err))
+	})
+
+	return threatLists, nil
+}
+
+// initClickEnrichers builds the enrichment stages enabled in config
+func initClickEnrichers(ctx context.Context, cfg config.EnrichmentConfig,
+	metricsCollector metrics.Metrics, logger *zap.Logger) ([]domain.ClickEnricher, error) {
+
+	var enrichers []domain.ClickEnricher
+
+	if cfg.GeoIP.File != "" {
+		geoDB, err := geoip.New(geoip.Config{
+			File:            cfg.GeoIP.File,
+			RefreshInterval: cfg.GeoIP.RefreshInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+		go geoDB.Watch(ctx, func(err error) {
+			logger.Error("Failed to reload GeoIP database", zap.Error(err))
+		})
+		enrichers = append(enrichers, enrich.NewGeoIP(geoDB, metricsCollector, logger))
+	}
+
+	if cfg.UserAgent.Enabled {
+		parser, err := useragent.New(useragent.Config{
+			RulesFile: cfg.UserAgent.RulesFile,
+			CacheSize: cfg.UserAgent.CacheSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		enrichers = append(enrichers, enrich.NewUserAgent(parser))
+	}
+
+	// After UserAgent, which the bot enricher builds on
+	if cfg.BotDetection.Enabled {
+		detector, err := botdetect.New(botdetect.Config{
+			Signatures:      cfg.BotDetection.Signatures,
+			RangesFile:      cfg.BotDetection.RangesFile,
+			RefreshInterval: cfg.BotDetection.RefreshInterval,
+			RateLimit:       cfg.BotDetection.RateLimit,
+			RateWindow:      cfg.BotDetection.RateWindow,
+		})
+		if err != nil {
+			return nil, err
+		}
+		go detector.Watch(ctx, func(err error) {
+			logger.Error("Failed to reload bot IP ranges", zap.Error(err))
+		})
+		enrichers = append(enrichers, enrich.NewBot(detector))
+	}
+
+	return enrichers, nil
+}
+
+// initDomainPolicy loads the domain allow/deny rules and hot-reloads them
+// from the policy file or, without one, from config.yaml
+func initDomainPolicy(ctx context.Context, cfg config.DomainPolicyConfig,
+	logger *zap.Logger) (*validator.DomainPolicy, error) {
+
+	domainPolicy, err := validator.NewDomainPolicy(validator.DomainPolicyConfig{
+		Mode:            cfg.Mode,
+		Rules:           cfg.Rules,
+		File:            cfg.File,
+		RefreshInterval: cfg.RefreshInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	onError := func(err error) {
+		logger.Error("Failed to reload domain policy", zap.Error(err))
+	}
+
+	if cfg.File != "" {
+		go domainPolicy.Watch(ctx, onError)
+		return domainPolicy, nil
+	}
+
+	config.Watch(func(updated *config.Config) {
+		policy := updated.Validator.DomainPolicy
+		if err := domainPolicy.SetRules(policy.Mode, policy.Rules); err != nil {
+			onError(err)
+			return
+		}
+		logger.Info("Reloaded domain policy", zap.Int("rules", len(policy.Rules)))
+	}, onError)
+
+	return domainPolicy, nil
+}
+
+// newEventsConfig validates the event formats chosen in the Kafka config
+func newEventsConfig(cfg config.KafkaConfig) (events.Config, error) {
+	encoding, err := events.ParseEncoding(cfg.Encoding)
+	if err != nil {
+		return events.Config{}, err
+	}
+	cloudEvents, err := events.ParseCloudEventsMode(cfg.CloudEvents)
+	if err != nil {
+		return events.Config{}, err
+	}
+
+	topicEncodings := make(map[string]events.Encoding)
+	for _, te := range cfg.TopicEncodings {
+		if topicEncodings[te.Topic], err = events.ParseEncoding(te.Encoding); err != nil {
+			return events.Config{}, fmt.Errorf("topic %s: %w", te.Topic, err)
+		}
+	}
+
+	return events.Config{
+		Producer:          cfg.Producer,
+		Encoding:          encoding,
+		TopicEncodings:    topicEncodings,
+		CloudEvents:       cloudEvents,
+		CloudEventsSource: cfg.CloudEventsSource,
+	}, nil
+}
+
 func initRedis(cfg config.RedisConfig) *redis.Client {
 	return redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
@@ -0,0 +1,150 @@
+// Package analytics consumes url.clicked events and rolls them up into
+// per-link hourly and daily click aggregates.
+package analytics
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+// Unknown is stored for dimensions a click carries no value for
+const Unknown = "unknown"
+
+// Click is one click reduced to the dimensions it is aggregated by
+type Click struct {
+	ShortCode      string
+	OccurredAt     time.Time
+	ReferrerDomain string
+	Country        string
+	Device         string
+	Browser        string
+	// Visitor identifies the client for unique-visitor estimates, "" when
+	// unknown
+	Visitor string
+}
+
+// Store persists click aggregates
+type Store interface {
+	// RecordClick adds the click to its hourly and daily aggregates unless
+	// eventID has been recorded before. It reports whether the event was
+	// new; recording the ID and the aggregates is atomic.
+	RecordClick(ctx context.Context, eventID string, click *Click) (bool, error)
+}
+
+// Processor turns url.clicked messages into aggregate updates
+type Processor struct {
+	store   Store
+	metrics metrics.Metrics
+	logger  *zap.Logger
+}
+
+func NewProcessor(store Store, metrics metrics.Metrics, logger *zap.Logger) *Processor {
+	return &Processor{
+		store:   store,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+// Handle records one message. Messages that cannot be decoded are logged
+// and skipped so they don't block the partition; store errors are returned
+// so the message is retried.
+func (p *Processor) Handle(ctx context.Context, msg *events.Message) error {
+	env, err := events.Decode(msg)
+	if err != nil {
+		p.metrics.IncrementCounter("click_events_invalid_total")
+		p.logger.Warn("Skipping undecodable click event",
+			zap.String("topic", msg.Topic), zap.String("key", msg.Key), zap.Error(err))
+		return nil
+	}
+
+	clicked, ok := env.Data.(*events.URLClicked)
+	if !ok || env.ID == "" {
+		p.metrics.IncrementCounter("click_events_invalid_total")
+		p.logger.Warn("Skipping click event without id or click data",
+			zap.String("event_id", env.ID), zap.String("event_type", env.Type))
+		return nil
+	}
+
+	// Aggregates count human clicks only; bots are counted on the URL
+	if clicked.IsBot {
+		p.metrics.IncrementCounter("click_events_bot_total")
+		return nil
+	}
+
+	recorded, err := p.store.RecordClick(ctx, env.ID, NewClick(clicked, env.OccurredAt))
+	if err != nil {
+		p.metrics.IncrementCounter("click_events_failed_total")
+		return err
+	}
+
+	if recorded {
+		p.metrics.IncrementCounter("click_events_processed_total")
+	} else {
+		p.metrics.IncrementCounter("click_events_duplicate_total")
+		p.logger.Debug("Skipping duplicate click event", zap.String("event_id", env.ID))
+	}
+
+	return nil
+}
+
+// NewClick extracts the aggregation dimensions of a click event
+func NewClick(event *events.URLClicked, occurredAt time.Time) *Click {
+	return &Click{
+		ShortCode:      event.ShortCode,
+		OccurredAt:     occurredAt.UTC(),
+		ReferrerDomain: referrerDomain(event.Referrer),
+		Country:        orUnknown(event.Country),
+		Device:         orUnknown(event.DeviceType),
+		Browser:        orUnknown(event.Browser),
+		Visitor:        visitorID(event.IPAddress, event.UserAgent),
+	}
+}
+
+// visitorID approximates a visitor by client IP and user agent
+func visitorID(ip, userAgent string) string {
+	if ip == "" {
+		return ""
+	}
+	return ip + "|" + userAgent
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return Unknown
+	}
+	return value
+}
+
+// referrerDomain is the host of the referrer without "www.", "direct" for
+// clicks without one
+func referrerDomain(referrer string) string {
+	if referrer == "" {
+		return "direct"
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return Unknown
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// HourBucket and DayBucket are the starts of the aggregate buckets t
+// falls in
+func HourBucket(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
+
+func DayBucket(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
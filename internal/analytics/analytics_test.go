@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+var clickTime = time.Date(2026, 5, 4, 13, 45, 0, 0, time.UTC)
+
+// failingStore fails RecordClick while fail is set
+type failingStore struct {
+	*MemoryStore
+	fail  bool
+	calls int
+}
+
+var errStoreDown = errors.New("database is down")
+
+func (s *failingStore) RecordClick(ctx context.Context, eventID string, click *Click) (bool, error) {
+	s.calls++
+	if s.fail {
+		return false, errStoreDown
+	}
+	return s.MemoryStore.RecordClick(ctx, eventID, click)
+}
+
+func clickMessage(t *testing.T, id string, clicked *events.URLClicked) *events.Message {
+	t.Helper()
+
+	value, err := events.EncodingJSON.Encode(&events.Envelope{
+		ID:            id,
+		Type:          events.EventTypeURLClicked,
+		SchemaVersion: events.SchemaVersion,
+		OccurredAt:    clickTime,
+		Producer:      "test",
+		Data:          clicked,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &events.Message{
+		Topic:   events.TopicURLClicked,
+		Key:     clicked.ShortCode,
+		Value:   value,
+		Headers: map[string]string{events.HeaderContentType: events.ContentTypeJSON},
+	}
+}
+
+func humanClick() *events.URLClicked {
+	return &events.URLClicked{
+		ShortCode:  "abc123",
+		UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) Safari/605.1.15",
+		IPAddress:  "203.0.113.7",
+		Referrer:   "https://www.example.org/post",
+		Country:    "US",
+		DeviceType: "desktop",
+		Browser:    "Safari",
+	}
+}
+
+func dailyClicks(store *MemoryStore) int64 {
+	var total int64
+	for _, count := range store.Daily() {
+		total += count
+	}
+	return total
+}
+
+func TestProcessorAppliesDuplicateEventOnce(t *testing.T) {
+	store := NewMemoryStore()
+	collector := metrics.NewInMemoryMetrics()
+	processor := NewProcessor(store, collector, zap.NewNop())
+
+	// Redelivery after a rebalance carries the same event ID
+	source := NewMemorySource(
+		clickMessage(t, "event-1", humanClick()),
+		clickMessage(t, "event-1", humanClick()),
+		clickMessage(t, "event-2", humanClick()),
+	)
+	if err := source.Consume(context.Background(), processor.Handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := source.Committed(); got != 3 {
+		t.Errorf("Committed() = %d, want 3", got)
+	}
+	if got := dailyClicks(store); got != 2 {
+		t.Errorf("daily clicks = %d, want 2", got)
+	}
+
+	key := AggregateKey{
+		ShortCode:      "abc123",
+		Bucket:         HourBucket(clickTime),
+		ReferrerDomain: "example.org",
+		Country:        "US",
+		Device:         "desktop",
+		Browser:        "Safari",
+	}
+	if got := store.Hourly()[key]; got != 2 {
+		t.Errorf("hourly clicks for %+v = %d, want 2 (aggregates %v)", key, got, store.Hourly())
+	}
+
+	counters := collector.GetCounters()
+	if counters["click_events_processed_total"] != 2 || counters["click_events_duplicate_total"] != 1 {
+		t.Errorf("counters = %v, want 2 processed and 1 duplicate", counters)
+	}
+}
+
+func TestProcessorDoesNotCommitWhenStoreFails(t *testing.T) {
+	store := &failingStore{MemoryStore: NewMemoryStore(), fail: true}
+	processor := NewProcessor(store, metrics.NewInMemoryMetrics(), zap.NewNop())
+
+	source := NewMemorySource(
+		clickMessage(t, "event-1", humanClick()),
+		clickMessage(t, "event-2", humanClick()),
+	)
+
+	err := source.Consume(context.Background(), processor.Handle)
+	if !errors.Is(err, errStoreDown) {
+		t.Fatalf("Consume() error = %v, want %v", err, errStoreDown)
+	}
+	if got := source.Committed(); got != 0 {
+		t.Errorf("Committed() after a failed write = %d, want 0", got)
+	}
+	if store.calls != 1 {
+		t.Errorf("store called %d times, want processing to stop at the failed message", store.calls)
+	}
+
+	// The failed message is delivered again once the store recovers
+	store.fail = false
+	if err := source.Consume(context.Background(), processor.Handle); err != nil {
+		t.Fatal(err)
+	}
+	if got := source.Committed(); got != 2 {
+		t.Errorf("Committed() after recovery = %d, want 2", got)
+	}
+	if got := dailyClicks(store.MemoryStore); got != 2 {
+		t.Errorf("daily clicks = %d, want 2", got)
+	}
+}
+
+func TestProcessorSkipsBotClicks(t *testing.T) {
+	store := &failingStore{MemoryStore: NewMemoryStore()}
+	collector := metrics.NewInMemoryMetrics()
+	processor := NewProcessor(store, collector, zap.NewNop())
+
+	bot := humanClick()
+	bot.UserAgent = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+	bot.IsBot = true
+	bot.BotReason = "user_agent:slackbot"
+
+	source := NewMemorySource(
+		clickMessage(t, "event-1", bot),
+		clickMessage(t, "event-2", humanClick()),
+	)
+	if err := source.Consume(context.Background(), processor.Handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := source.Committed(); got != 2 {
+		t.Errorf("Committed() = %d, want 2", got)
+	}
+	if store.calls != 1 {
+		t.Errorf("store called %d times, want only for the human click", store.calls)
+	}
+	if got := dailyClicks(store.MemoryStore); got != 1 {
+		t.Errorf("daily clicks = %d, want 1", got)
+	}
+	if got := collector.GetCounters()["click_events_bot_total"]; got != 1 {
+		t.Errorf("click_events_bot_total = %d, want 1", got)
+	}
+}
+
+func TestProcessorSkipsUndecodableEvents(t *testing.T) {
+	store := NewMemoryStore()
+	collector := metrics.NewInMemoryMetrics()
+	processor := NewProcessor(store, collector, zap.NewNop())
+
+	source := NewMemorySource(
+		&events.Message{Topic: events.TopicURLClicked, Value: []byte("{not json")},
+		clickMessage(t, "", humanClick()),
+		clickMessage(t, "event-1", humanClick()),
+	)
+	if err := source.Consume(context.Background(), processor.Handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := source.Committed(); got != 3 {
+		t.Errorf("Committed() = %d, want 3", got)
+	}
+	if got := dailyClicks(store); got != 1 {
+		t.Errorf("daily clicks = %d, want 1", got)
+	}
+	if got := collector.GetCounters()["click_events_invalid_total"]; got != 2 {
+		t.Errorf("click_events_invalid_total = %d, want 2", got)
+	}
+}
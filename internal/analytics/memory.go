@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/hll"
+)
+
+// AggregateKey identifies one aggregate row
+type AggregateKey struct {
+	ShortCode      string
+	Bucket         time.Time
+	ReferrerDomain string
+	Country        string
+	Device         string
+	Browser        string
+}
+
+// MemoryStore keeps aggregates in memory, for tests and local runs
+type MemoryStore struct {
+	mu        sync.Mutex
+	processed map[string]bool
+	hourly    map[AggregateKey]int64
+	daily     map[AggregateKey]int64
+	uniques   map[uniquesKey]*hll.Sketch
+}
+
+type uniquesKey struct {
+	shortCode string
+	daily     bool
+	bucket    time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		processed: make(map[string]bool),
+		hourly:    make(map[AggregateKey]int64),
+		daily:     make(map[AggregateKey]int64),
+		uniques:   make(map[uniquesKey]*hll.Sketch),
+	}
+}
+
+func (s *MemoryStore) RecordClick(ctx context.Context, eventID string, click *Click) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.processed[eventID] {
+		return false, nil
+	}
+	s.processed[eventID] = true
+
+	key := AggregateKey{
+		ShortCode:      click.ShortCode,
+		ReferrerDomain: click.ReferrerDomain,
+		Country:        click.Country,
+		Device:         click.Device,
+		Browser:        click.Browser,
+	}
+	key.Bucket = HourBucket(click.OccurredAt)
+	s.hourly[key]++
+	key.Bucket = DayBucket(click.OccurredAt)
+	s.daily[key]++
+
+	if click.Visitor != "" {
+		s.addVisitor(uniquesKey{click.ShortCode, false, HourBucket(click.OccurredAt)}, click.Visitor)
+		s.addVisitor(uniquesKey{click.ShortCode, true, DayBucket(click.OccurredAt)}, click.Visitor)
+	}
+
+	return true, nil
+}
+
+func (s *MemoryStore) addVisitor(key uniquesKey, visitor string) {
+	sketch, ok := s.uniques[key]
+	if !ok {
+		sketch = hll.New()
+		s.uniques[key] = sketch
+	}
+	sketch.AddString(visitor)
+}
+
+// UniqueVisitors estimates the distinct visitors of a link in the hourly
+// or daily bucket starting at bucket
+func (s *MemoryStore) UniqueVisitors(shortCode string, daily bool, bucket time.Time) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sketch, ok := s.uniques[uniquesKey{shortCode, daily, bucket}]; ok {
+		return sketch.Estimate()
+	}
+	return 0
+}
+
+// Hourly and Daily return copies of the aggregates
+func (s *MemoryStore) Hourly() map[AggregateKey]int64 {
+	return s.snapshot(s.hourly)
+}
+
+func (s *MemoryStore) Daily() map[AggregateKey]int64 {
+	return s.snapshot(s.daily)
+}
+
+func (s *MemoryStore) snapshot(m map[AggregateKey]int64) map[AggregateKey]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[AggregateKey]int64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
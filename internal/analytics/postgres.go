@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/hll"
+)
+
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) RecordClick(ctx context.Context, eventID string, click *Click) (bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO processed_click_events (event_id)
+        VALUES ($1)
+        ON CONFLICT (event_id) DO NOTHING`, eventID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record click event id: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	buckets := []struct {
+		table   string
+		uniques string
+		bucket  time.Time
+	}{
+		{"click_stats_hourly", "click_uniques_hourly", HourBucket(click.OccurredAt)},
+		{"click_stats_daily", "click_uniques_daily", DayBucket(click.OccurredAt)},
+	}
+	for _, b := range buckets {
+		query := fmt.Sprintf(`
+            INSERT INTO %s (short_code, bucket, referrer_domain, country,
+                            device, browser, clicks)
+            VALUES ($1, $2, $3, $4, $5, $6, 1)
+            ON CONFLICT (short_code, bucket, referrer_domain, country, device, browser)
+            DO UPDATE SET clicks = %s.clicks + 1`, b.table, b.table)
+
+		_, err := tx.ExecContext(ctx, query,
+			click.ShortCode, b.bucket, click.ReferrerDomain,
+			click.Country, click.Device, click.Browser)
+		if err != nil {
+			return false, fmt.Errorf("failed to update %s: %w", b.table, err)
+		}
+
+		if click.Visitor != "" {
+			if err := addVisitor(ctx, tx, b.uniques, click.ShortCode, b.bucket, click.Visitor); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit click: %w", err)
+	}
+
+	return true, nil
+}
+
+// addVisitor adds the visitor to the bucket's HyperLogLog sketch. The row
+// is locked while the sketch is merged in Go.
+func addVisitor(ctx context.Context, tx *sqlx.Tx, table, shortCode string,
+	bucket time.Time, visitor string) error {
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        INSERT INTO %s (short_code, bucket, sketch)
+        VALUES ($1, $2, ''::bytea)
+        ON CONFLICT (short_code, bucket) DO NOTHING`, table), shortCode, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to create %s row: %w", table, err)
+	}
+
+	var data []byte
+	err = tx.GetContext(ctx, &data, fmt.Sprintf(`
+        SELECT sketch FROM %s
+        WHERE short_code = $1 AND bucket = $2
+        FOR UPDATE`, table), shortCode, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to lock %s row: %w", table, err)
+	}
+
+	sketch, err := hll.Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s sketch: %w", table, err)
+	}
+	sketch.AddString(visitor)
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+        UPDATE %s SET sketch = $3
+        WHERE short_code = $1 AND bucket = $2`, table), shortCode, bucket, sketch.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// PruneProcessed forgets event IDs processed before the given time. Kafka
+// redeliveries arrive well within the retention window, so older IDs
+// are no longer needed for dedupe.
+func (s *PostgresStore) PruneProcessed(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM processed_click_events WHERE processed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune processed click events: %w", err)
+	}
+
+	return result.RowsAffected()
+}
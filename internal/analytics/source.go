@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+)
+
+// Handler processes one message. A source only commits a message's offset
+// once its Handler returned nil for it.
+type Handler func(ctx context.Context, msg *events.Message) error
+
+// Source delivers messages to a Handler in order
+type Source interface {
+	Consume(ctx context.Context, handler Handler) error
+}
+
+type KafkaSourceConfig struct {
+	Brokers      []string
+	Topic        string
+	GroupID      string
+	RetryBackoff time.Duration // Wait before reprocessing after a failed message
+}
+
+// KafkaSource consumes a topic as a member of a consumer group with auto
+// commit disabled; offsets are committed one message at a time after the
+// handler succeeds
+type KafkaSource struct {
+	group  sarama.ConsumerGroup
+	logger *zap.Logger
+	config KafkaSourceConfig
+}
+
+func NewKafkaSource(logger *zap.Logger, config KafkaSourceConfig) (*KafkaSource, error) {
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 5 * time.Second
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = false
+
+	group, err := sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return &KafkaSource{group: group, logger: logger, config: config}, nil
+}
+
+// Consume runs until ctx is cancelled. A failed message ends the group
+// session, so it is consumed again from the last committed offset after
+// RetryBackoff.
+func (s *KafkaSource) Consume(ctx context.Context, handler Handler) error {
+	h := &groupHandler{handler: handler}
+	for {
+		err := s.group.Consume(ctx, []string{s.config.Topic}, h)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return err
+		}
+		if err != nil {
+			s.logger.Error("Click consumer session failed", zap.Error(err))
+		}
+
+		if h.failed() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(s.config.RetryBackoff):
+			}
+		}
+	}
+}
+
+func (s *KafkaSource) Close() error {
+	return s.group.Close()
+}
+
+type groupHandler struct {
+	handler Handler
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.mu.Lock()
+	h.lastErr = nil
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *groupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case <-sess.Context().Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if err := h.handler(sess.Context(), toMessage(msg)); err != nil {
+				h.mu.Lock()
+				h.lastErr = err
+				h.mu.Unlock()
+				return fmt.Errorf("failed to process %s/%d@%d: %w",
+					msg.Topic, msg.Partition, msg.Offset, err)
+			}
+
+			sess.MarkMessage(msg, "")
+			sess.Commit()
+		}
+	}
+}
+
+func (h *groupHandler) failed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastErr != nil
+}
+
+func toMessage(msg *sarama.ConsumerMessage) *events.Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	return &events.Message{
+		Topic:   msg.Topic,
+		Key:     string(msg.Key),
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// MemorySource is an in-process Source for tests and local runs. Consume
+// delivers the messages added so far, stopping at the first one the
+// handler fails, which is delivered again by the next Consume.
+type MemorySource struct {
+	mu        sync.Mutex
+	messages  []*events.Message
+	committed int
+}
+
+func NewMemorySource(messages ...*events.Message) *MemorySource {
+	return &MemorySource{messages: messages}
+}
+
+// Add appends messages to the source
+func (s *MemorySource) Add(messages ...*events.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, messages...)
+}
+
+func (s *MemorySource) Consume(ctx context.Context, handler Handler) error {
+	for {
+		s.mu.Lock()
+		if s.committed >= len(s.messages) {
+			s.mu.Unlock()
+			return nil
+		}
+		msg := s.messages[s.committed]
+		s.mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := handler(ctx, msg); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.committed++
+		s.mu.Unlock()
+	}
+}
+
+// Committed returns how many messages have been handled successfully
+func (s *MemorySource) Committed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.committed
+}
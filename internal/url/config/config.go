@@ -2,16 +2,24 @@ package config
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Kafka    KafkaConfig
-	Service  ServiceConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	Kafka      KafkaConfig
+	Service    ServiceConfig
+	KeyPool    KeyPoolConfig
+	Validator  ValidatorConfig
+	Purge      PurgeConfig
+	Outbox     OutboxConfig
+	Analytics  AnalyticsConfig
+	Enrichment EnrichmentConfig
 }
 
 type ServerConfig struct {
@@ -36,12 +44,128 @@ type RedisConfig struct {
 }
 
 type KafkaConfig struct {
-	Brokers []string
+	Brokers           []string
+	Producer          string // Producer name in event envelopes, defaults to the hostname
+	Encoding          string // "json" (default) or "protobuf"
+	TopicEncodings    []TopicEncodingConfig
+	CloudEvents       string // "" (off), "structured" or "binary"
+	CloudEventsSource string
+}
+
+// TopicEncodingConfig overrides the event encoding of one topic
+type TopicEncodingConfig struct {
+	Topic    string
+	Encoding string
 }
 
 type ServiceConfig struct {
-	BaseURL   string
-	MachineID int64
+	BaseURL          string
+	MachineID        int64
+	Generator        string // "base62" (default), "snowflake", "pool" or "hashids"
+	ShortCodeLength  int
+	HashidsSalt      string
+	HashidsMinLength int
+	Charset          string // "base62" (default) or "unambiguous"
+	DenylistFile     string // Blocked words for generated codes and aliases
+	Canonicalization CanonicalizationConfig
+	RiskPolicy       string // "off" (default), "flag" or "block" deceptive URLs
+	RiskThreshold    int
+}
+
+// CanonicalizationConfig enables the lossy URL normalization steps used for
+// dedupe
+type CanonicalizationConfig struct {
+	// SortQueryParams is off by default, so URLs differing only in
+	// parameter order are not deduplicated
+	SortQueryParams     bool
+	StripTrackingParams bool
+	TrackingParams      []string // Defaults to urlnorm.DefaultTrackingParams
+}
+
+type KeyPoolConfig struct {
+	Size           int
+	RefillBatch    int
+	LowWatermark   int
+	RefillInterval time.Duration
+}
+
+// PurgeConfig controls hard deletion of soft-deleted URLs
+type PurgeConfig struct {
+	Enabled   bool
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// OutboxConfig tunes the relay that publishes outbox events to Kafka
+type OutboxConfig struct {
+	BatchSize    int
+	PollInterval time.Duration
+	MaxBackoff   time.Duration
+	Retention    time.Duration // How long sent events are kept
+}
+
+// AnalyticsConfig configures the click-consumer
+type AnalyticsConfig struct {
+	ConsumerGroup   string
+	RetryBackoff    time.Duration // Wait before retrying a click that failed to save
+	DedupeRetention time.Duration // How long processed event IDs are remembered
+}
+
+// EnrichmentConfig configures the fields derived from clicks before they
+// are published
+type EnrichmentConfig struct {
+	GeoIP        GeoIPConfig
+	UserAgent    UserAgentConfig
+	BotDetection BotDetectionConfig
+}
+
+type GeoIPConfig struct {
+	File            string // .mmdb database, GeoIP enrichment is off without one
+	RefreshInterval time.Duration
+}
+
+type UserAgentConfig struct {
+	Enabled   bool
+	RulesFile string // uap-core regexes.yaml, defaults to the built-in rules
+	CacheSize int    // Parsed agents kept in memory
+}
+
+type BotDetectionConfig struct {
+	Enabled         bool
+	Signatures      []string // User-Agent substrings added to the built-in list
+	RangesFile      string   // Crawler IP ranges, one CIDR per line
+	RefreshInterval time.Duration
+	RateLimit       int // Hits per IP per rateWindow before clicks count as bots, 0 disables
+	RateWindow      time.Duration
+}
+
+type ValidatorConfig struct {
+	ResolveTimeout       time.Duration
+	AllowPrivateNetworks bool
+	ThreatLists          ThreatListConfig
+	DomainPolicy         DomainPolicyConfig
+	FollowRedirects      bool
+	MaxRedirects         int
+	RedirectTimeout      time.Duration
+	ProtectedDomains     []string // Brands scored for look-alikes
+	MaxSubdomainDepth    int
+}
+
+type DomainPolicyConfig struct {
+	Mode            string // "deny" (default) or "allow"
+	Rules           []string
+	File            string // Optional YAML file overriding Mode and Rules
+	RefreshInterval time.Duration
+}
+
+type ThreatListConfig struct {
+	HashPrefixFiles []string
+	DomainFiles     []string
+	URLFiles        []string
+	RefreshInterval time.Duration
+	// SafeBrowsingAPIKey enables fullHashes:find to confirm hash prefix
+	// hits; without it every prefix hit is reported as unsafe
+	SafeBrowsingAPIKey string
 }
 
 func Load() (*Config, error) {
@@ -65,6 +189,20 @@ func Load() (*Config, error) {
 	return &config, nil
 }
 
+// Watch calls onChange with the re-read configuration whenever the config
+// file changes on disk
+func Watch(onChange func(*Config), onError func(error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		var config Config
+		if err := viper.Unmarshal(&config); err != nil {
+			onError(fmt.Errorf("failed to unmarshal config: %w", err))
+			return
+		}
+		onChange(&config)
+	})
+	viper.WatchConfig()
+}
+
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
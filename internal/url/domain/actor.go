@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context recording who is making a change, for the
+// revision history
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or "" if unknown
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
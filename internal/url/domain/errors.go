@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Sentinel errors returned by the URL service. Handlers map them to
+// transport-specific status codes with errors.Is.
+var (
+	ErrInvalidAlias        = errors.New("invalid custom alias")
+	ErrShortCodeConflict   = errors.New("short code is already in use")
+	ErrURLAlreadyShortened = errors.New("URL is already shortened for this user")
+	ErrURLNotFound         = errors.New("URL not found")
+	ErrURLExpired          = errors.New("URL has expired")
+	ErrInvalidUpdate       = errors.New("invalid update")
+	ErrRevisionNotFound    = errors.New("revision not found")
+	ErrInvalidStatsRequest = errors.New("invalid stats request")
+)
@@ -7,5 +7,15 @@ type EventPublisher interface {
 	PublishURLCreated(ctx context.Context, url *URL) error
 	PublishURLUpdated(ctx context.Context, url *URL, updatedFields []string) error
 	PublishURLClicked(ctx context.Context, event *ClickEvent) error
+	PublishURLDeleted(ctx context.Context, url *URL, reason string) error
+	PublishURLRestored(ctx context.Context, url *URL) error
+	PublishURLPurged(ctx context.Context, url *URL) error
 	Close() error
 }
+
+// ClickEnricher derives additional fields of a click, such as its location,
+// before the click is published. Enrichment is best effort: fields it
+// cannot derive are left empty.
+type ClickEnricher interface {
+	EnrichClick(ctx context.Context, event *ClickEvent)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// Bucket sizes of a click series
+const (
+	StatsGranularityHour = "hour"
+	StatsGranularityDay  = "day"
+	StatsGranularityWeek = "week"
+)
+
+// URLStatsRequest selects the clicks of a link in [From, To)
+type URLStatsRequest struct {
+	ShortCode   string
+	Granularity string
+	From        time.Time
+	To          time.Time
+	Limit       int // Entries per top list
+}
+
+// URLStats summarizes the clicks of a link over a time range. Unique
+// visitor counts are HyperLogLog estimates.
+type URLStats struct {
+	ShortCode      string        `json:"short_code"`
+	Granularity    string        `json:"granularity"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	TotalClicks    int64         `json:"total_clicks"`
+	UniqueVisitors int64         `json:"unique_visitors"`
+	Series         []StatsBucket `json:"series"`
+	TopReferrers   []StatsCount  `json:"top_referrers"`
+	TopCountries   []StatsCount  `json:"top_countries"`
+	TopDevices     []StatsCount  `json:"top_devices"`
+	TopBrowsers    []StatsCount  `json:"top_browsers"`
+}
+
+// StatsBucket is one point of a click series
+type StatsBucket struct {
+	Start          time.Time `json:"start"`
+	Clicks         int64     `json:"clicks"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+}
+
+// StatsCount is the number of clicks with one value of a dimension
+type StatsCount struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// StatsBucketStart returns the start of the granularity bucket t falls in.
+// Buckets are in UTC and weeks start on Monday.
+func StatsBucketStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case StatsGranularityHour:
+		return t.Truncate(time.Hour)
+	case StatsGranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextStatsBucket returns the start of the bucket after the one starting
+// at start
+func NextStatsBucket(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case StatsGranularityHour:
+		return start.Add(time.Hour)
+	case StatsGranularityWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+type traceKey struct{}
+
+// TraceContext is the W3C trace context (traceparent and tracestate) of the
+// request being served
+type TraceContext struct {
+	TraceParent string
+	TraceState  string
+}
+
+// WithTraceContext returns a context carrying tc, so events published
+// while serving the request can be correlated with it
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context set by
+// WithTraceContext, or the zero value if there is none
+func TraceContextFromContext(ctx context.Context) TraceContext {
+	tc, _ := ctx.Value(traceKey{}).(TraceContext)
+	return tc
+}
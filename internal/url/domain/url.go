@@ -9,17 +9,23 @@ import (
 
 // URL represents a shortened URL entity
 type URL struct {
-	ID          int64      `json:"id" db:"id"`
-	ShortCode   string     `json:"short_code" db:"short_code"`
-	OriginalURL string     `json:"original_url" db:"original_url"`
-	UserID      int64      `json:"user_id" db:"user_id"` // NOT pointer - matches schema
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"` // Pointer - nullable in schema
-	ClickCount  int64      `json:"click_count" db:"click_count"`
-	IsActive    bool       `json:"is_active" db:"is_active"`
-	Metadata    JSONB      `json:"metadata" db:"metadata"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"` // NOT pointer - matches schema
-	DeletedAt   time.Time  `json:"deleted_at" db:"deleted_at"`
+	ID          int64  `json:"id" db:"id"`
+	ShortCode   string `json:"short_code" db:"short_code"`
+	OriginalURL string `json:"original_url" db:"original_url"`
+	// CanonicalURL is the normalized form of OriginalURL used for dedupe
+	CanonicalURL string     `json:"canonical_url,omitempty" db:"canonical_url"`
+	UserID       int64      `json:"user_id" db:"user_id"` // NOT pointer - matches schema
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at" db:"expires_at"` // Pointer - nullable in schema
+	ClickCount   int64      `json:"click_count" db:"click_count"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	Metadata     JSONB      `json:"metadata" db:"metadata"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"` // NOT pointer - matches schema
+	DeletedAt    time.Time  `json:"deleted_at" db:"deleted_at"`
+
+	// BotClickCount counts clicks classified as automated; ClickCount
+	// excludes them
+	BotClickCount int64 `json:"bot_click_count" db:"bot_click_count"`
 }
 
 // JSONB handles JSON data for PostgreSQL
@@ -55,10 +61,21 @@ func (j *JSONB) Scan(value interface{}) error {
 
 // CreateURLRequest represents the request to create a new URL
 type CreateURLRequest struct {
-	URL       string                 `json:"url" binding:"required,url"`
-	UserID    int64                  `json:"user_id" binding:"required"`
-	ExpiresIn *int                   `json:"expires_in,omitempty"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	URL         string                 `json:"url" binding:"required,url"`
+	UserID      int64                  `json:"user_id" binding:"required"`
+	ExpiresIn   *int                   `json:"expires_in,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CustomAlias string                 `json:"custom_alias,omitempty"` // Optional vanity short code
+}
+
+// UpdateURLRequest represents a partial update of an existing URL. Nil
+// fields are left unchanged.
+type UpdateURLRequest struct {
+	OriginalURL    *string                `json:"original_url,omitempty"` // Retarget the short code
+	ExpiresAt      *time.Time             `json:"expires_at,omitempty"`
+	ClearExpiresAt bool                   `json:"clear_expires_at,omitempty"` // Remove the expiry
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`         // Replaces existing metadata
+	IsActive       *bool                  `json:"is_active,omitempty"`
 }
 
 // URLResponse represents the API response for URL operations
@@ -69,6 +86,25 @@ type URLResponse struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	ClickCount  int64      `json:"click_count"`
+	// BotClickCount counts clicks classified as automated; ClickCount
+	// excludes them
+	BotClickCount int64 `json:"bot_click_count"`
+}
+
+// ValidateURLRequest represents a request to pre-screen a URL
+type ValidateURLRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// ValidationResult reports whether a URL would be accepted for shortening.
+// IsSafe is only checked for valid URLs.
+type ValidationResult struct {
+	URL     string `json:"url"`
+	IsValid bool   `json:"is_valid"`
+	IsSafe  bool   `json:"is_safe"`
+	Reason  string `json:"reason,omitempty"`
+	Rule    string `json:"rule,omitempty"`  // Check that rejected the URL
+	Match   string `json:"match,omitempty"` // Policy or threat list entry that matched
 }
 
 // ClickEvent represents a URL click event for analytics
@@ -78,4 +114,65 @@ type ClickEvent struct {
 	IPAddress string    `json:"ip_address"`
 	Referrer  string    `json:"referrer,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Filled in by ClickEnrichers from IPAddress; empty when unknown
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Region  string `json:"region,omitempty"`  // ISO 3166-2 subdivision code
+	City    string `json:"city,omitempty"`
+
+	// Filled in by ClickEnrichers from UserAgent; empty when unknown
+	DeviceType     string `json:"device_type,omitempty"` // desktop, mobile, tablet or bot
+	OS             string `json:"os,omitempty"`
+	OSVersion      string `json:"os_version,omitempty"`
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+
+	// IsBot is set by ClickEnrichers for automated clicks, which are
+	// counted in BotClickCount instead of ClickCount
+	IsBot     bool   `json:"is_bot"`
+	BotReason string `json:"bot_reason,omitempty"`
+}
+
+// Reasons carried by url.deleted events
+const (
+	DeleteReasonUserDelete    = "user_delete"
+	DeleteReasonExpirySweep   = "expiry_sweep"
+	DeleteReasonAbuseTakedown = "abuse_takedown"
+)
+
+// Revision actions recorded in URLRevision.Action
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionRestore = "restore"
+)
+
+// URLSnapshot is the editable state of a URL at one revision
+type URLSnapshot struct {
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	Metadata    JSONB      `json:"metadata,omitempty"`
+}
+
+// URLRevision records one create or update of a URL
+type URLRevision struct {
+	Revision      int          `json:"revision"`
+	ShortCode     string       `json:"short_code"`
+	Action        string       `json:"action"`
+	ChangedBy     string       `json:"changed_by,omitempty"`
+	ChangedFields []string     `json:"changed_fields,omitempty"`
+	Before        *URLSnapshot `json:"before,omitempty"` // Nil for the create revision
+	After         *URLSnapshot `json:"after"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// Snapshot captures the editable state of the URL
+func (u *URL) Snapshot() *URLSnapshot {
+	return &URLSnapshot{
+		OriginalURL: u.OriginalURL,
+		ExpiresAt:   u.ExpiresAt,
+		IsActive:    u.IsActive,
+		Metadata:    u.Metadata,
+	}
 }
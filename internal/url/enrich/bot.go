@@ -0,0 +1,29 @@
+package enrich
+
+import (
+	"context"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/botdetect"
+	"github.com/umanagarjuna/go-url-shortener/pkg/useragent"
+)
+
+// Bot flags automated clicks. It runs after UserAgent, if that is
+// enabled, so agents the UA rules classify as bots are flagged too.
+type Bot struct {
+	detector *botdetect.Detector
+}
+
+func NewBot(detector *botdetect.Detector) *Bot {
+	return &Bot{detector: detector}
+}
+
+func (b *Bot) EnrichClick(ctx context.Context, event *domain.ClickEvent) {
+	verdict := b.detector.Classify(event.IPAddress, event.UserAgent)
+	if !verdict.IsBot && event.DeviceType == useragent.DeviceBot {
+		verdict = botdetect.Verdict{IsBot: true, Reason: botdetect.ReasonUserAgent + ":" + event.Browser}
+	}
+
+	event.IsBot = verdict.IsBot
+	event.BotReason = verdict.Reason
+}
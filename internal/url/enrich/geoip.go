@@ -0,0 +1,47 @@
+// Package enrich adds derived fields to click events before they are
+// published.
+package enrich
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"github.com/umanagarjuna/go-url-shortener/pkg/geoip"
+)
+
+// GeoIP sets the country, region and city of a click from its IP address
+type GeoIP struct {
+	db      *geoip.DB
+	metrics metrics.Metrics
+	logger  *zap.Logger
+}
+
+func NewGeoIP(db *geoip.DB, metrics metrics.Metrics, logger *zap.Logger) *GeoIP {
+	return &GeoIP{
+		db:      db,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+func (g *GeoIP) EnrichClick(ctx context.Context, event *domain.ClickEvent) {
+	location, ok, err := g.db.LookupString(event.IPAddress)
+	if err != nil {
+		g.metrics.IncrementCounterWithLabels("click_geoip_lookups_total", map[string]string{"result": "error"})
+		g.logger.Warn("GeoIP lookup failed",
+			zap.Error(err), zap.String("ip_address", event.IPAddress))
+		return
+	}
+	if !ok {
+		g.metrics.IncrementCounterWithLabels("click_geoip_lookups_total", map[string]string{"result": "miss"})
+		return
+	}
+
+	g.metrics.IncrementCounterWithLabels("click_geoip_lookups_total", map[string]string{"result": "hit"})
+	event.Country = location.Country
+	event.Region = location.Region
+	event.City = location.City
+}
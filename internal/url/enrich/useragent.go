@@ -0,0 +1,39 @@
+package enrich
+
+import (
+	"context"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/useragent"
+)
+
+// UserAgent sets the device type, OS and browser of a click from its
+// User-Agent header
+type UserAgent struct {
+	parser *useragent.Parser
+}
+
+func NewUserAgent(parser *useragent.Parser) *UserAgent {
+	return &UserAgent{parser: parser}
+}
+
+func (u *UserAgent) EnrichClick(ctx context.Context, event *domain.ClickEvent) {
+	if event.UserAgent == "" {
+		return
+	}
+
+	client := u.parser.Parse(event.UserAgent)
+	event.DeviceType = client.DeviceType
+	event.OS = known(client.OS.Family)
+	event.OSVersion = client.OS.Version()
+	event.Browser = known(client.Browser.Family)
+	event.BrowserVersion = client.Browser.Version()
+}
+
+// known maps the uap-core "Other" family to an empty field
+func known(family string) string {
+	if family == useragent.Other {
+		return ""
+	}
+	return family
+}
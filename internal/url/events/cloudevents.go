@@ -0,0 +1,120 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudEventsMode selects the CloudEvents 1.0 Kafka protocol binding mode.
+// With either mode set, events are emitted as CloudEvents with a JSON data
+// payload instead of an Envelope.
+type CloudEventsMode string
+
+const (
+	CloudEventsOff CloudEventsMode = ""
+	// CloudEventsStructured puts the whole event, attributes and data, in a
+	// JSON message value
+	CloudEventsStructured CloudEventsMode = "structured"
+	// CloudEventsBinary puts the attributes in ce_ headers and only the
+	// data in the message value
+	CloudEventsBinary CloudEventsMode = "binary"
+)
+
+const (
+	CloudEventsSpecVersion   = "1.0"
+	ContentTypeCloudEvents   = "application/cloudevents+json; charset=UTF-8"
+	DefaultCloudEventsSource = "/url-service"
+)
+
+// ParseCloudEventsMode validates a mode name; "" disables CloudEvents
+func ParseCloudEventsMode(s string) (CloudEventsMode, error) {
+	switch mode := CloudEventsMode(s); mode {
+	case CloudEventsOff, CloudEventsStructured, CloudEventsBinary:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown CloudEvents mode %q", s)
+	}
+}
+
+// cloudEvent is the structured-mode JSON form of an event. Schema version,
+// producer and the W3C trace context travel as extension attributes.
+type cloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	SchemaVersion   int         `json:"schemaversion"`
+	Producer        string      `json:"producer,omitempty"`
+	TraceParent     string      `json:"traceparent,omitempty"`
+	TraceState      string      `json:"tracestate,omitempty"`
+	Data            interface{} `json:"data"`
+}
+
+// CloudEventType is the CloudEvents type of an event type: the dotted form
+// used for topics, "url.created" for url_created
+func CloudEventType(eventType string) string {
+	return strings.ReplaceAll(eventType, "_", ".")
+}
+
+// encodeCloudEvent serializes env as a CloudEvent about subject, returning
+// the message value and headers for the mode
+func encodeCloudEvent(env *Envelope, mode CloudEventsMode, source, subject string) ([]byte, map[string]string, error) {
+	ce := cloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              env.ID,
+		Source:          source,
+		Type:            CloudEventType(env.Type),
+		Subject:         subject,
+		Time:            env.OccurredAt.Format(time.RFC3339Nano),
+		DataContentType: ContentTypeJSON,
+		SchemaVersion:   env.SchemaVersion,
+		Producer:        env.Producer,
+		Data:            env.Data,
+	}
+	if env.Trace != nil {
+		ce.TraceParent = env.Trace.TraceParent
+		ce.TraceState = env.Trace.TraceState
+	}
+
+	if mode == CloudEventsStructured {
+		value, err := json.Marshal(ce)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		return value, map[string]string{HeaderContentType: ContentTypeCloudEvents}, nil
+	}
+
+	value, err := json.Marshal(ce.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	headers := map[string]string{
+		HeaderContentType:  ce.DataContentType,
+		"ce_specversion":   ce.SpecVersion,
+		"ce_id":            ce.ID,
+		"ce_source":        ce.Source,
+		"ce_type":          ce.Type,
+		"ce_time":          ce.Time,
+		"ce_schemaversion": strconv.Itoa(ce.SchemaVersion),
+	}
+	optional := map[string]string{
+		"ce_subject":     ce.Subject,
+		"ce_producer":    ce.Producer,
+		"ce_traceparent": ce.TraceParent,
+		"ce_tracestate":  ce.TraceState,
+	}
+	for key, value := range optional {
+		if value != "" {
+			headers[key] = value
+		}
+	}
+
+	return value, headers, nil
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	eventspb "github.com/umanagarjuna/go-url-shortener/api/proto/events/v1"
+)
+
+// Encoding selects how envelopes are serialized on a topic
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "json"
+	EncodingProtobuf Encoding = "protobuf" // api/proto/events/v1 Envelope
+)
+
+// Content types sent in the content-type message header
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// ParseEncoding validates an encoding name; "" means JSON
+func ParseEncoding(s string) (Encoding, error) {
+	switch Encoding(s) {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingProtobuf:
+		return EncodingProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown event encoding %q", s)
+	}
+}
+
+// ContentType is the MIME type of envelopes in this encoding
+func (e Encoding) ContentType() string {
+	if e == EncodingProtobuf {
+		return ContentTypeProtobuf
+	}
+	return ContentTypeJSON
+}
+
+// Encode serializes the envelope
+func (e Encoding) Encode(env *Envelope) ([]byte, error) {
+	if e == EncodingProtobuf {
+		msg, err := env.toProto()
+		if err != nil {
+			return nil, err
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+func (env *Envelope) toProto() (*eventspb.Envelope, error) {
+	msg := &eventspb.Envelope{
+		Id:            env.ID,
+		Type:          env.Type,
+		SchemaVersion: int32(env.SchemaVersion),
+		OccurredAt:    timestamppb.New(env.OccurredAt),
+		Producer:      env.Producer,
+	}
+	if env.Trace != nil {
+		msg.Trace = &eventspb.TraceContext{
+			Traceparent: env.Trace.TraceParent,
+			Tracestate:  env.Trace.TraceState,
+		}
+	}
+
+	switch data := env.Data.(type) {
+	case *URLCreated:
+		msg.Data = &eventspb.Envelope_UrlCreated{UrlCreated: &eventspb.URLCreated{
+			ShortCode:   data.ShortCode,
+			OriginalUrl: data.OriginalURL,
+			UserId:      data.UserID,
+			ExpiresAt:   optionalTimestamp(data.ExpiresAt),
+		}}
+	case *URLUpdated:
+		var metadata *structpb.Struct
+		if len(data.Metadata) > 0 {
+			var err error
+			if metadata, err = structpb.NewStruct(data.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to convert metadata: %w", err)
+			}
+		}
+		msg.Data = &eventspb.Envelope_UrlUpdated{UrlUpdated: &eventspb.URLUpdated{
+			ShortCode:     data.ShortCode,
+			OriginalUrl:   data.OriginalURL,
+			UserId:        data.UserID,
+			IsActive:      data.IsActive,
+			ExpiresAt:     optionalTimestamp(data.ExpiresAt),
+			Metadata:      metadata,
+			UpdatedFields: data.UpdatedFields,
+		}}
+	case *URLClicked:
+		msg.Data = &eventspb.Envelope_UrlClicked{UrlClicked: &eventspb.URLClicked{
+			ShortCode: data.ShortCode,
+			UserAgent: data.UserAgent,
+			IpAddress: data.IPAddress,
+			Referrer:  data.Referrer,
+			Country:   data.Country,
+			Region:    data.Region,
+			City:      data.City,
+
+			DeviceType:     data.DeviceType,
+			Os:             data.OS,
+			OsVersion:      data.OSVersion,
+			Browser:        data.Browser,
+			BrowserVersion: data.BrowserVersion,
+
+			IsBot:     data.IsBot,
+			BotReason: data.BotReason,
+		}}
+	case *URLDeleted:
+		msg.Data = &eventspb.Envelope_UrlDeleted{UrlDeleted: &eventspb.URLDeleted{
+			ShortCode:   data.ShortCode,
+			OriginalUrl: data.OriginalURL,
+			UserId:      data.UserID,
+			Reason:      data.Reason,
+			DeletedAt:   timestamppb.New(data.DeletedAt),
+		}}
+	case *URLRestored:
+		msg.Data = &eventspb.Envelope_UrlRestored{UrlRestored: &eventspb.URLRestored{
+			ShortCode:   data.ShortCode,
+			OriginalUrl: data.OriginalURL,
+			UserId:      data.UserID,
+			ExpiresAt:   optionalTimestamp(data.ExpiresAt),
+		}}
+	case *URLPurged:
+		msg.Data = &eventspb.Envelope_UrlPurged{UrlPurged: &eventspb.URLPurged{
+			ShortCode: data.ShortCode,
+			UserId:    data.UserID,
+			DeletedAt: timestamppb.New(data.DeletedAt),
+		}}
+	default:
+		return nil, fmt.Errorf("no protobuf schema for %T", env.Data)
+	}
+
+	return msg, nil
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
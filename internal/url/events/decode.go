@@ -0,0 +1,236 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	eventspb "github.com/umanagarjuna/go-url-shortener/api/proto/events/v1"
+)
+
+// Decode parses a message produced by EventPublisher in any of its
+// formats: JSON or protobuf envelopes and structured or binary CloudEvents.
+// Data of the returned envelope is a pointer to the payload type matching
+// Type.
+func Decode(msg *Message) (*Envelope, error) {
+	contentType := msg.Headers[HeaderContentType]
+
+	switch {
+	case msg.Headers["ce_id"] != "":
+		return decodeBinaryCloudEvent(msg)
+	case strings.HasPrefix(contentType, "application/cloudevents+json"):
+		return decodeStructuredCloudEvent(msg.Value)
+	case contentType == ContentTypeProtobuf:
+		return decodeProtoEnvelope(msg.Value)
+	default:
+		return decodeJSONEnvelope(msg.Value)
+	}
+}
+
+func decodeJSONEnvelope(value []byte) (*Envelope, error) {
+	var raw struct {
+		Envelope
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	env := raw.Envelope
+	data, err := decodeData(env.Type, raw.Data)
+	if err != nil {
+		return nil, err
+	}
+	env.Data = data
+
+	return &env, nil
+}
+
+func decodeStructuredCloudEvent(value []byte) (*Envelope, error) {
+	var raw struct {
+		cloudEvent
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CloudEvent: %w", err)
+	}
+
+	return fromCloudEvent(&raw.cloudEvent, raw.Data)
+}
+
+func decodeBinaryCloudEvent(msg *Message) (*Envelope, error) {
+	ce := &cloudEvent{
+		SpecVersion:     msg.Headers["ce_specversion"],
+		ID:              msg.Headers["ce_id"],
+		Source:          msg.Headers["ce_source"],
+		Type:            msg.Headers["ce_type"],
+		Subject:         msg.Headers["ce_subject"],
+		Time:            msg.Headers["ce_time"],
+		DataContentType: msg.Headers[HeaderContentType],
+		Producer:        msg.Headers["ce_producer"],
+		TraceParent:     msg.Headers["ce_traceparent"],
+		TraceState:      msg.Headers["ce_tracestate"],
+	}
+	if v := msg.Headers["ce_schemaversion"]; v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ce_schemaversion %q", v)
+		}
+		ce.SchemaVersion = version
+	}
+
+	return fromCloudEvent(ce, msg.Value)
+}
+
+func fromCloudEvent(ce *cloudEvent, data json.RawMessage) (*Envelope, error) {
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("unsupported CloudEvents specversion %q", ce.SpecVersion)
+	}
+
+	occurredAt, err := time.Parse(time.RFC3339Nano, ce.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CloudEvent time %q: %w", ce.Time, err)
+	}
+
+	env := &Envelope{
+		ID:            ce.ID,
+		Type:          strings.ReplaceAll(ce.Type, ".", "_"),
+		SchemaVersion: ce.SchemaVersion,
+		OccurredAt:    occurredAt,
+		Producer:      ce.Producer,
+	}
+	if ce.TraceParent != "" {
+		env.Trace = &TraceContext{TraceParent: ce.TraceParent, TraceState: ce.TraceState}
+	}
+
+	if env.Data, err = decodeData(env.Type, data); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// decodeData unmarshals a JSON payload into the type for eventType
+func decodeData(eventType string, data json.RawMessage) (interface{}, error) {
+	var payload interface{}
+	switch eventType {
+	case EventTypeURLCreated:
+		payload = &URLCreated{}
+	case EventTypeURLUpdated:
+		payload = &URLUpdated{}
+	case EventTypeURLClicked:
+		payload = &URLClicked{}
+	case EventTypeURLDeleted:
+		payload = &URLDeleted{}
+	case EventTypeURLRestored:
+		payload = &URLRestored{}
+	case EventTypeURLPurged:
+		payload = &URLPurged{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s data: %w", eventType, err)
+	}
+
+	return payload, nil
+}
+
+func decodeProtoEnvelope(value []byte) (*Envelope, error) {
+	var msg eventspb.Envelope
+	if err := proto.Unmarshal(value, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	env := &Envelope{
+		ID:            msg.GetId(),
+		Type:          msg.GetType(),
+		SchemaVersion: int(msg.GetSchemaVersion()),
+		OccurredAt:    msg.GetOccurredAt().AsTime(),
+		Producer:      msg.GetProducer(),
+	}
+	if trace := msg.GetTrace(); trace != nil {
+		env.Trace = &TraceContext{TraceParent: trace.GetTraceparent(), TraceState: trace.GetTracestate()}
+	}
+
+	switch data := msg.Data.(type) {
+	case *eventspb.Envelope_UrlCreated:
+		env.Data = &URLCreated{
+			ShortCode:   data.UrlCreated.GetShortCode(),
+			OriginalURL: data.UrlCreated.GetOriginalUrl(),
+			UserID:      data.UrlCreated.GetUserId(),
+			ExpiresAt:   optionalTime(data.UrlCreated.GetExpiresAt()),
+		}
+	case *eventspb.Envelope_UrlUpdated:
+		updated := &URLUpdated{
+			ShortCode:     data.UrlUpdated.GetShortCode(),
+			OriginalURL:   data.UrlUpdated.GetOriginalUrl(),
+			UserID:        data.UrlUpdated.GetUserId(),
+			IsActive:      data.UrlUpdated.GetIsActive(),
+			ExpiresAt:     optionalTime(data.UrlUpdated.GetExpiresAt()),
+			UpdatedFields: data.UrlUpdated.GetUpdatedFields(),
+		}
+		if metadata := data.UrlUpdated.GetMetadata(); metadata != nil {
+			updated.Metadata = metadata.AsMap()
+		}
+		env.Data = updated
+	case *eventspb.Envelope_UrlClicked:
+		env.Data = &URLClicked{
+			ShortCode: data.UrlClicked.GetShortCode(),
+			UserAgent: data.UrlClicked.GetUserAgent(),
+			IPAddress: data.UrlClicked.GetIpAddress(),
+			Referrer:  data.UrlClicked.GetReferrer(),
+			Country:   data.UrlClicked.GetCountry(),
+			Region:    data.UrlClicked.GetRegion(),
+			City:      data.UrlClicked.GetCity(),
+
+			DeviceType:     data.UrlClicked.GetDeviceType(),
+			OS:             data.UrlClicked.GetOs(),
+			OSVersion:      data.UrlClicked.GetOsVersion(),
+			Browser:        data.UrlClicked.GetBrowser(),
+			BrowserVersion: data.UrlClicked.GetBrowserVersion(),
+
+			IsBot:     data.UrlClicked.GetIsBot(),
+			BotReason: data.UrlClicked.GetBotReason(),
+		}
+	case *eventspb.Envelope_UrlDeleted:
+		env.Data = &URLDeleted{
+			ShortCode:   data.UrlDeleted.GetShortCode(),
+			OriginalURL: data.UrlDeleted.GetOriginalUrl(),
+			UserID:      data.UrlDeleted.GetUserId(),
+			Reason:      data.UrlDeleted.GetReason(),
+			DeletedAt:   data.UrlDeleted.GetDeletedAt().AsTime(),
+		}
+	case *eventspb.Envelope_UrlRestored:
+		env.Data = &URLRestored{
+			ShortCode:   data.UrlRestored.GetShortCode(),
+			OriginalURL: data.UrlRestored.GetOriginalUrl(),
+			UserID:      data.UrlRestored.GetUserId(),
+			ExpiresAt:   optionalTime(data.UrlRestored.GetExpiresAt()),
+		}
+	case *eventspb.Envelope_UrlPurged:
+		env.Data = &URLPurged{
+			ShortCode: data.UrlPurged.GetShortCode(),
+			UserID:    data.UrlPurged.GetUserId(),
+			DeletedAt: data.UrlPurged.GetDeletedAt().AsTime(),
+		}
+	default:
+		return nil, fmt.Errorf("event %s has no data", env.ID)
+	}
+
+	return env, nil
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
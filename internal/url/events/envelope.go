@@ -0,0 +1,96 @@
+package events
+
+import (
+	"time"
+)
+
+// Event types carried in Envelope.Type
+const (
+	EventTypeURLCreated  = "url_created"
+	EventTypeURLUpdated  = "url_updated"
+	EventTypeURLClicked  = "url_clicked"
+	EventTypeURLDeleted  = "url_deleted"
+	EventTypeURLRestored = "url_restored"
+	EventTypeURLPurged   = "url_purged"
+)
+
+// SchemaVersion is the version of the payload schemas below. Adding
+// optional fields keeps the version; removing or changing the meaning of a
+// field bumps it.
+const SchemaVersion = 1
+
+// Envelope is the wire format of every event. Data holds one of the
+// payload types below, matching Type.
+type Envelope struct {
+	ID            string        `json:"id"`
+	Type          string        `json:"event_type"`
+	SchemaVersion int           `json:"schema_version"`
+	OccurredAt    time.Time     `json:"occurred_at"`
+	Producer      string        `json:"producer"`
+	Trace         *TraceContext `json:"trace,omitempty"`
+	Data          interface{}   `json:"data"`
+}
+
+// TraceContext is the W3C trace context of the request that caused the
+// event
+type TraceContext struct {
+	TraceParent string `json:"traceparent"`
+	TraceState  string `json:"tracestate,omitempty"`
+}
+
+type URLCreated struct {
+	ShortCode   string     `json:"short_code"`
+	OriginalURL string     `json:"original_url"`
+	UserID      int64      `json:"user_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type URLUpdated struct {
+	ShortCode     string                 `json:"short_code"`
+	OriginalURL   string                 `json:"original_url"`
+	UserID        int64                  `json:"user_id"`
+	IsActive      bool                   `json:"is_active"`
+	ExpiresAt     *time.Time             `json:"expires_at,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	UpdatedFields []string               `json:"updated_fields"`
+}
+
+type URLClicked struct {
+	ShortCode string `json:"short_code"`
+	UserAgent string `json:"user_agent"`
+	IPAddress string `json:"ip_address"`
+	Referrer  string `json:"referrer"`
+	Country   string `json:"country,omitempty"`
+	Region    string `json:"region,omitempty"`
+	City      string `json:"city,omitempty"`
+
+	DeviceType     string `json:"device_type,omitempty"`
+	OS             string `json:"os,omitempty"`
+	OSVersion      string `json:"os_version,omitempty"`
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+
+	IsBot     bool   `json:"is_bot"`
+	BotReason string `json:"bot_reason,omitempty"`
+}
+
+type URLDeleted struct {
+	ShortCode   string    `json:"short_code"`
+	OriginalURL string    `json:"original_url"`
+	UserID      int64     `json:"user_id"`
+	Reason      string    `json:"reason"` // One of the domain.DeleteReason constants
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type URLRestored struct {
+	ShortCode   string     `json:"short_code"`
+	OriginalURL string     `json:"original_url"`
+	UserID      int64      `json:"user_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type URLPurged struct {
+	ShortCode string    `json:"short_code"`
+	UserID    int64     `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
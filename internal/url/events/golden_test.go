@@ -0,0 +1,220 @@
+package events
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	eventspb "github.com/umanagarjuna/go-url-shortener/api/proto/events/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenFormats are the publisher configurations whose output is pinned
+var goldenFormats = []struct {
+	name   string
+	config Config
+}{
+	{"json", Config{Producer: "url-service-0", Encoding: EncodingJSON}},
+	{"protobuf", Config{Producer: "url-service-0", Encoding: EncodingProtobuf}},
+	{"structured", Config{Producer: "url-service-0", CloudEvents: CloudEventsStructured}},
+	{"binary", Config{Producer: "url-service-0", CloudEvents: CloudEventsBinary}},
+}
+
+func goldenEnvelopes() []struct {
+	topic string
+	env   *Envelope
+} {
+	occurredAt := time.Date(2026, 3, 14, 15, 9, 26, 535000000, time.UTC)
+	expiresAt := time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)
+	trace := &TraceContext{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TraceState:  "vendor=value",
+	}
+
+	envelope := func(id, eventType string, data interface{}) *Envelope {
+		return &Envelope{
+			ID:            id,
+			Type:          eventType,
+			SchemaVersion: SchemaVersion,
+			OccurredAt:    occurredAt,
+			Producer:      "url-service-0",
+			Trace:         trace,
+			Data:          data,
+		}
+	}
+
+	return []struct {
+		topic string
+		env   *Envelope
+	}{
+		{TopicURLCreated, envelope("0195f1c4-0000-7000-8000-000000000001", EventTypeURLCreated, &URLCreated{
+			ShortCode:   "aB3xYz",
+			OriginalURL: "https://example.com/landing?ref=golden",
+			UserID:      42,
+			ExpiresAt:   &expiresAt,
+		})},
+		{TopicURLUpdated, envelope("0195f1c4-0000-7000-8000-000000000002", EventTypeURLUpdated, &URLUpdated{
+			ShortCode:     "aB3xYz",
+			OriginalURL:   "https://example.com/new",
+			UserID:        42,
+			IsActive:      true,
+			ExpiresAt:     &expiresAt,
+			Metadata:      map[string]interface{}{"campaign": "spring", "priority": float64(2), "pinned": true},
+			UpdatedFields: []string{"original_url", "metadata"},
+		})},
+		{TopicURLClicked, envelope("0195f1c4-0000-7000-8000-000000000003", EventTypeURLClicked, &URLClicked{
+			ShortCode:      "aB3xYz",
+			UserAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X)",
+			IPAddress:      "203.0.113.7",
+			Referrer:       "https://news.example.org/",
+			Country:        "US",
+			Region:         "CA",
+			City:           "San Francisco",
+			DeviceType:     "mobile",
+			OS:             "iOS",
+			OSVersion:      "17.4",
+			Browser:        "Mobile Safari",
+			BrowserVersion: "17.4",
+			IsBot:          true,
+			BotReason:      "user_agent:slackbot",
+		})},
+		{TopicURLDeleted, envelope("0195f1c4-0000-7000-8000-000000000004", EventTypeURLDeleted, &URLDeleted{
+			ShortCode:   "aB3xYz",
+			OriginalURL: "https://example.com/new",
+			UserID:      42,
+			Reason:      "user",
+			DeletedAt:   occurredAt,
+		})},
+		{TopicURLDeleted, envelope("0195f1c4-0000-7000-8000-000000000005", EventTypeURLRestored, &URLRestored{
+			ShortCode:   "aB3xYz",
+			OriginalURL: "https://example.com/new",
+			UserID:      42,
+			ExpiresAt:   &expiresAt,
+		})},
+		{TopicURLDeleted, envelope("0195f1c4-0000-7000-8000-000000000006", EventTypeURLPurged, &URLPurged{
+			ShortCode: "aB3xYz",
+			UserID:    42,
+			DeletedAt: occurredAt,
+		})},
+	}
+}
+
+// TestGolden pins the wire format of every event in every publisher format
+// and checks that Decode reads each one back. Run with -update to rewrite
+// the files after an intended change.
+func TestGolden(t *testing.T) {
+	for _, format := range goldenFormats {
+		publisher := NewEventPublisherWithSender(nil, format.config)
+
+		for _, tc := range goldenEnvelopes() {
+			name := CloudEventType(tc.env.Type) + "." + format.name
+			t.Run(name, func(t *testing.T) {
+				msg, err := publisher.encode(tc.env, tc.topic, "aB3xYz")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				path := filepath.Join("testdata", name+".golden")
+				if *update {
+					if err := os.WriteFile(path, formatGolden(msg), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+				}
+				golden, err := parseGolden(data)
+				if err != nil {
+					t.Fatalf("%s: %v", path, err)
+				}
+
+				if !reflect.DeepEqual(msg.Headers, golden.Headers) {
+					t.Errorf("headers = %v, golden %v", msg.Headers, golden.Headers)
+				}
+				compareValue(t, format.config, msg.Value, golden.Value)
+
+				decoded, err := Decode(golden)
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+				if !reflect.DeepEqual(decoded, tc.env) {
+					t.Errorf("Decode = %+v (data %+v), want %+v (data %+v)",
+						decoded, decoded.Data, tc.env, tc.env.Data)
+				}
+			})
+		}
+	}
+}
+
+// compareValue compares encoded values byte for byte, except protobuf which
+// does not guarantee a stable order for map fields such as metadata
+func compareValue(t *testing.T, config Config, got, golden []byte) {
+	t.Helper()
+
+	if config.CloudEvents != CloudEventsOff || config.Encoding != EncodingProtobuf {
+		if !bytes.Equal(got, golden) {
+			t.Errorf("value = %s\ngolden  %s", got, golden)
+		}
+		return
+	}
+
+	var gotMsg, goldenMsg eventspb.Envelope
+	if err := proto.Unmarshal(got, &gotMsg); err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(golden, &goldenMsg); err != nil {
+		t.Fatalf("golden value: %v", err)
+	}
+	if !proto.Equal(&gotMsg, &goldenMsg) {
+		t.Errorf("value = %v\ngolden  %v", &gotMsg, &goldenMsg)
+	}
+}
+
+// formatGolden writes a message as sorted "key: value" header lines, a
+// blank line and the value
+func formatGolden(msg *Message) []byte {
+	keys := make([]string, 0, len(msg.Headers))
+	for key := range msg.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", key, msg.Headers[key])
+	}
+	b.WriteString("\n")
+	b.Write(msg.Value)
+
+	return b.Bytes()
+}
+
+func parseGolden(data []byte) (*Message, error) {
+	head, value, ok := bytes.Cut(data, []byte("\n\n"))
+	if !ok {
+		return nil, fmt.Errorf("missing blank line after headers")
+	}
+
+	msg := &Message{Headers: make(map[string]string), Value: value}
+	for _, line := range strings.Split(string(head), "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		msg.Headers[key] = value
+	}
+
+	return msg, nil
+}
@@ -2,127 +2,257 @@ package events
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"time"
 
-	"github.com/IBM/sarama"
+	"github.com/google/uuid"
 
 	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
 )
 
 const (
 	TopicURLCreated = "url.created"
-	TopicURLUpdated = "url.updated" // Add this line
+	TopicURLUpdated = "url.updated"
 	TopicURLClicked = "url.clicked"
 	TopicURLDeleted = "url.deleted"
 )
 
-type EventPublisher struct {
-	producer sarama.SyncProducer
+// Message headers describing the envelope, so consumers can route and
+// decode without parsing the value
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventID       = "event-id"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+)
+
+type Config struct {
+	// Producer identifies this instance in the envelope. Defaults to the
+	// hostname.
+	Producer string
+	// Encoding is used for topics without an entry in TopicEncodings.
+	// Defaults to JSON.
+	Encoding       Encoding
+	TopicEncodings map[string]Encoding
+	// CloudEvents emits CloudEvents in the given mode instead of
+	// envelopes, ignoring the encodings
+	CloudEvents CloudEventsMode
+	// CloudEventsSource is the CloudEvents source attribute. Defaults to
+	// DefaultCloudEventsSource.
+	CloudEventsSource string
 }
 
-func NewEventPublisher(brokers []string) (*EventPublisher, error) {
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
-	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Retry.Max = 5
+// EventPublisher wraps domain events in an Envelope, encodes them for their
+// topic and hands them to a Sender
+type EventPublisher struct {
+	sender         Sender
+	producer       string
+	encoding       Encoding
+	topicEncodings map[string]Encoding
+	cloudEvents    CloudEventsMode
+	source         string
+}
 
-	producer, err := sarama.NewSyncProducer(brokers, config)
+// NewEventPublisher publishes straight to Kafka
+func NewEventPublisher(brokers []string, config Config) (*EventPublisher, error) {
+	sender, err := NewKafkaSender(brokers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create producer: %w", err)
+		return nil, err
 	}
 
-	return &EventPublisher{producer: producer}, nil
+	return NewEventPublisherWithSender(sender, config), nil
 }
 
-func (p *EventPublisher) PublishURLCreated(ctx context.Context,
-	url *domain.URL) error {
+// NewEventPublisherWithSender publishes through sender, for example into
+// the transactional outbox
+func NewEventPublisherWithSender(sender Sender, config Config) *EventPublisher {
+	if config.Producer == "" {
+		config.Producer, _ = os.Hostname()
+	}
+	if config.Encoding == "" {
+		config.Encoding = EncodingJSON
+	}
+	if config.CloudEventsSource == "" {
+		config.CloudEventsSource = DefaultCloudEventsSource
+	}
 
-	event := map[string]interface{}{
-		"event_type": "url_created",
-		"timestamp":  url.CreatedAt,
-		"data": map[string]interface{}{
-			"short_code":   url.ShortCode,
-			"original_url": url.OriginalURL,
-			"user_id":      url.UserID,
-			"expires_at":   url.ExpiresAt,
-		},
+	return &EventPublisher{
+		sender:         sender,
+		producer:       config.Producer,
+		encoding:       config.Encoding,
+		topicEncodings: config.TopicEncodings,
+		cloudEvents:    config.CloudEvents,
+		source:         config.CloudEventsSource,
 	}
+}
 
-	return p.publish(TopicURLCreated, url.ShortCode, event)
+func (p *EventPublisher) PublishURLCreated(ctx context.Context,
+	url *domain.URL) error {
+
+	return p.publish(ctx, TopicURLCreated, url.ShortCode, EventTypeURLCreated, url.CreatedAt,
+		&URLCreated{
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			UserID:      url.UserID,
+			ExpiresAt:   url.ExpiresAt,
+		})
 }
 
-// Add this new method
 func (p *EventPublisher) PublishURLUpdated(ctx context.Context,
 	url *domain.URL, updatedFields []string) error {
 
-	data := map[string]interface{}{
-		"short_code":     url.ShortCode,
-		"original_url":   url.OriginalURL,
-		"updated_fields": updatedFields,
+	data := &URLUpdated{
+		ShortCode:     url.ShortCode,
+		OriginalURL:   url.OriginalURL,
+		UserID:        url.UserID,
+		IsActive:      url.IsActive,
+		ExpiresAt:     url.ExpiresAt,
+		UpdatedFields: updatedFields,
 	}
-	
-	data["user_id"] = url.UserID
-
-	if url.ExpiresAt != nil {
-		data["expires_at"] = url.ExpiresAt
-	}
-	if url.Metadata != nil && len(url.Metadata) > 0 {
-		// Convert JSONB to map[string]interface{}
-		metadata := make(map[string]interface{})
-		for k, v := range url.Metadata {
-			metadata[k] = v
-		}
-		data["metadata"] = metadata
+	if len(url.Metadata) > 0 {
+		data.Metadata = map[string]interface{}(url.Metadata)
 	}
 
-	event := map[string]interface{}{
-		"event_type": "url_updated",
-		"timestamp":  time.Now(),
-		"data":       data,
+	occurredAt := url.UpdatedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
 	}
 
-	return p.publish(TopicURLUpdated, url.ShortCode, event)
+	return p.publish(ctx, TopicURLUpdated, url.ShortCode, EventTypeURLUpdated, occurredAt, data)
 }
 
 func (p *EventPublisher) PublishURLClicked(ctx context.Context,
 	event *domain.ClickEvent) error {
 
-	kafkaEvent := map[string]interface{}{
-		"event_type": "url_clicked",
-		"timestamp":  event.Timestamp,
-		"data": map[string]interface{}{
-			"short_code": event.ShortCode,
-			"user_agent": event.UserAgent,
-			"ip_address": event.IPAddress,
-			"referrer":   event.Referrer,
-		},
-	}
+	return p.publish(ctx, TopicURLClicked, event.ShortCode, EventTypeURLClicked, event.Timestamp,
+		&URLClicked{
+			ShortCode: event.ShortCode,
+			UserAgent: event.UserAgent,
+			IPAddress: event.IPAddress,
+			Referrer:  event.Referrer,
+			Country:   event.Country,
+			Region:    event.Region,
+			City:      event.City,
+
+			DeviceType:     event.DeviceType,
+			OS:             event.OS,
+			OSVersion:      event.OSVersion,
+			Browser:        event.Browser,
+			BrowserVersion: event.BrowserVersion,
+
+			IsBot:     event.IsBot,
+			BotReason: event.BotReason,
+		})
+}
+
+// PublishURLDeleted announces a soft delete; reason is one of the
+// domain.DeleteReason constants
+func (p *EventPublisher) PublishURLDeleted(ctx context.Context,
+	url *domain.URL, reason string) error {
 
-	return p.publish(TopicURLClicked, event.ShortCode, kafkaEvent)
+	return p.publish(ctx, TopicURLDeleted, url.ShortCode, EventTypeURLDeleted, url.DeletedAt,
+		&URLDeleted{
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			UserID:      url.UserID,
+			Reason:      reason,
+			DeletedAt:   url.DeletedAt,
+		})
 }
 
-func (p *EventPublisher) publish(topic, key string, event interface{}) error {
-	data, err := json.Marshal(event)
+// PublishURLRestored announces that a soft-deleted URL is live again
+func (p *EventPublisher) PublishURLRestored(ctx context.Context,
+	url *domain.URL) error {
+
+	return p.publish(ctx, TopicURLDeleted, url.ShortCode, EventTypeURLRestored, time.Now(),
+		&URLRestored{
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			UserID:      url.UserID,
+			ExpiresAt:   url.ExpiresAt,
+		})
+}
+
+// PublishURLPurged announces that a soft-deleted URL was removed for good
+func (p *EventPublisher) PublishURLPurged(ctx context.Context,
+	url *domain.URL) error {
+
+	return p.publish(ctx, TopicURLDeleted, url.ShortCode, EventTypeURLPurged, time.Now(),
+		&URLPurged{
+			ShortCode: url.ShortCode,
+			UserID:    url.UserID,
+			DeletedAt: url.DeletedAt,
+		})
+}
+
+func (p *EventPublisher) publish(ctx context.Context, topic, key, eventType string,
+	occurredAt time.Time, data interface{}) error {
+
+	id, err := uuid.NewV7()
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return fmt.Errorf("failed to generate event id: %w", err)
 	}
 
-	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Key:   sarama.StringEncoder(key),
-		Value: sarama.ByteEncoder(data),
+	env := &Envelope{
+		ID:            id.String(),
+		Type:          eventType,
+		SchemaVersion: SchemaVersion,
+		OccurredAt:    occurredAt.UTC(),
+		Producer:      p.producer,
+		Data:          data,
+	}
+	if tc := domain.TraceContextFromContext(ctx); tc.TraceParent != "" {
+		env.Trace = &TraceContext{TraceParent: tc.TraceParent, TraceState: tc.TraceState}
 	}
 
-	_, _, err = p.producer.SendMessage(msg)
+	msg, err := p.encode(env, topic, key)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return err
 	}
 
-	return nil
+	return p.sender.Send(ctx, msg)
 }
 
+// encode builds the message for env in the configured format
+func (p *EventPublisher) encode(env *Envelope, topic, key string) (*Message, error) {
+	var err error
+	msg := &Message{Topic: topic, Key: key}
+	if p.cloudEvents != CloudEventsOff {
+		msg.Value, msg.Headers, err = encodeCloudEvent(env, p.cloudEvents, p.source, key)
+		if err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	encoding := p.encodingFor(topic)
+	if msg.Value, err = encoding.Encode(env); err != nil {
+		return nil, err
+	}
+	msg.Headers = map[string]string{
+		HeaderContentType:   encoding.ContentType(),
+		HeaderEventID:       env.ID,
+		HeaderEventType:     env.Type,
+		HeaderSchemaVersion: strconv.Itoa(env.SchemaVersion),
+	}
+
+	return msg, nil
+}
+
+func (p *EventPublisher) encodingFor(topic string) Encoding {
+	if encoding, ok := p.topicEncodings[topic]; ok {
+		return encoding
+	}
+	return p.encoding
+}
+
+// Close closes the sender if it holds resources of its own
 func (p *EventPublisher) Close() error {
-	return p.producer.Close()
+	if closer, ok := p.sender.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
 }
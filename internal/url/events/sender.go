@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// Message is an encoded event addressed to a topic. Key selects the
+// partition, so events with the same key are delivered in order.
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Sender delivers encoded events
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// KafkaSender sends messages with a synchronous Sarama producer
+type KafkaSender struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaSender(brokers []string) (*KafkaSender, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	return &KafkaSender{producer: producer}, nil
+}
+
+func (s *KafkaSender) Send(ctx context.Context, msg *Message) error {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers))
+	for key, value := range msg.Headers {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	_, _, err := s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   msg.Topic,
+		Key:     sarama.StringEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *KafkaSender) Close() error {
+	return s.producer.Close()
+}
+
+// TopicRouter sends messages for the listed topics through their own
+// Sender and everything else through Default
+type TopicRouter struct {
+	Default Sender
+	Topics  map[string]Sender
+}
+
+func (r *TopicRouter) Send(ctx context.Context, msg *Message) error {
+	if sender, ok := r.Topics[msg.Topic]; ok {
+		return sender.Send(ctx, msg)
+	}
+	return r.Default.Send(ctx, msg)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+)
+
+// ActorHeader identifies who is making a change, recorded in URL revisions
+const ActorHeader = "X-Actor"
+
+// actorMiddleware stores the caller's actor header in the request context
+func actorMiddleware(c *gin.Context) {
+	if actor := c.GetHeader(ActorHeader); actor != "" {
+		c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), actor))
+	}
+	c.Next()
+}
+
+// ActorUnaryInterceptor stores the x-actor metadata of gRPC calls in the
+// request context
+func ActorUnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if actors := md.Get(ActorHeader); len(actors) > 0 && actors[0] != "" {
+			ctx = domain.WithActor(ctx, actors[0])
+		}
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/validator"
+)
+
+// httpStatusFromError maps service errors to HTTP status codes
+func httpStatusFromError(err error) int {
+	var validationErr *validator.ValidationError
+	var unsafeErr *validator.UnsafeURLError
+	var deceptiveErr *validator.DeceptiveURLError
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidAlias), errors.Is(err, domain.ErrInvalidUpdate),
+		errors.Is(err, domain.ErrInvalidStatsRequest),
+		errors.As(err, &validationErr), errors.As(err, &unsafeErr),
+		errors.As(err, &deceptiveErr):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrURLNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrShortCodeConflict),
+		errors.Is(err, domain.ErrURLAlreadyShortened), errors.Is(err, domain.ErrURLExpired):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// grpcCodeFromError maps service errors to gRPC status codes
+func grpcCodeFromError(err error) codes.Code {
+	var validationErr *validator.ValidationError
+	var unsafeErr *validator.UnsafeURLError
+	var deceptiveErr *validator.DeceptiveURLError
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidAlias), errors.Is(err, domain.ErrInvalidUpdate),
+		errors.Is(err, domain.ErrInvalidStatsRequest),
+		errors.As(err, &validationErr), errors.As(err, &unsafeErr),
+		errors.As(err, &deceptiveErr):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrURLNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return codes.NotFound
+	case errors.Is(err, domain.ErrShortCodeConflict),
+		errors.Is(err, domain.ErrURLAlreadyShortened):
+		return codes.AlreadyExists
+	case errors.Is(err, domain.ErrURLExpired):
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
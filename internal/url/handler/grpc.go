@@ -2,6 +2,10 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -51,27 +55,17 @@ func (h *GRPCHandler) CreateURL(ctx context.Context,
 		domainReq.ExpiresIn = &expiresInInt
 	}
 
+	if req.CustomAlias != nil {
+		domainReq.CustomAlias = *req.CustomAlias
+	}
+
 	resp, err := h.service.CreateURL(ctx, domainReq)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal,
+		return nil, status.Errorf(grpcCodeFromError(err),
 			"failed to create URL: %v", err)
 	}
 
-	pbResp := &pb.URLResponse{
-		ShortCode:   resp.ShortCode,
-		ShortUrl:    resp.ShortURL,
-		OriginalUrl: resp.OriginalURL,
-		CreatedAt:   resp.CreatedAt.Unix(),
-		ClickCount:  resp.ClickCount,
-	}
-
-	// Handle ExpiresAt conversion
-	if resp.ExpiresAt != nil {
-		expiresAtUnix := resp.ExpiresAt.Unix()
-		pbResp.ExpiresAt = &expiresAtUnix
-	}
-
-	return pbResp, nil
+	return toPBURLResponse(resp), nil
 }
 
 func (h *GRPCHandler) GetURL(ctx context.Context,
@@ -79,7 +73,7 @@ func (h *GRPCHandler) GetURL(ctx context.Context,
 
 	resp, err := h.service.GetURL(ctx, req.ShortCode)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal,
+		return nil, status.Errorf(grpcCodeFromError(err),
 			"failed to get URL: %v", err)
 	}
 
@@ -87,12 +81,218 @@ func (h *GRPCHandler) GetURL(ctx context.Context,
 		return nil, status.Errorf(codes.NotFound, "URL not found")
 	}
 
+	return toPBURLResponse(resp), nil
+}
+
+func (h *GRPCHandler) ValidateURL(ctx context.Context,
+	req *pb.ValidateURLRequest) (*pb.ValidationResponse, error) {
+
+	if req.Url == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "url is required")
+	}
+
+	result, err := h.service.ValidateURL(ctx, req.Url)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal,
+			"failed to validate URL: %v", err)
+	}
+
+	pbResp := &pb.ValidationResponse{
+		IsValid: result.IsValid,
+		IsSafe:  result.IsSafe,
+	}
+
+	if result.Reason != "" {
+		pbResp.Reason = &result.Reason
+	}
+	if result.Rule != "" {
+		pbResp.Rule = &result.Rule
+	}
+
+	return pbResp, nil
+}
+
+func (h *GRPCHandler) DeleteURL(ctx context.Context,
+	req *pb.DeleteURLRequest) (*pb.DeleteURLResponse, error) {
+
+	if req.ShortCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "short_code is required")
+	}
+
+	if err := h.service.DeleteURL(ctx, req.ShortCode); err != nil {
+		return nil, status.Errorf(grpcCodeFromError(err),
+			"failed to delete URL: %v", err)
+	}
+
+	return &pb.DeleteURLResponse{}, nil
+}
+
+func (h *GRPCHandler) UpdateURL(ctx context.Context,
+	req *pb.UpdateURLRequest) (*pb.URLResponse, error) {
+
+	if req.ShortCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "short_code is required")
+	}
+
+	domainReq := &domain.UpdateURLRequest{
+		OriginalURL: req.OriginalUrl,
+		IsActive:    req.IsActive,
+	}
+
+	if req.ExpiresAt != nil {
+		if *req.ExpiresAt == 0 {
+			domainReq.ClearExpiresAt = true
+		} else {
+			expiresAt := time.Unix(*req.ExpiresAt, 0)
+			domainReq.ExpiresAt = &expiresAt
+		}
+	}
+
+	if req.Metadata != nil {
+		domainReq.Metadata = make(map[string]interface{})
+		for key, value := range req.Metadata {
+			domainReq.Metadata[key] = value
+		}
+	}
+
+	resp, err := h.service.UpdateURL(ctx, req.ShortCode, domainReq)
+	if err != nil {
+		return nil, status.Errorf(grpcCodeFromError(err),
+			"failed to update URL: %v", err)
+	}
+
+	return toPBURLResponse(resp), nil
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (h *GRPCHandler) ListUserURLs(ctx context.Context,
+	req *pb.ListUserURLsRequest) (*pb.ListUserURLsResponse, error) {
+
+	if req.UserId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id must be a positive integer")
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token")
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	urls, err := h.service.GetUserURLs(ctx, req.UserId, pageSize+1, offset)
+	if err != nil {
+		return nil, status.Errorf(grpcCodeFromError(err),
+			"failed to list URLs: %v", err)
+	}
+
+	pbResp := &pb.ListUserURLsResponse{}
+	if len(urls) > pageSize {
+		urls = urls[:pageSize]
+		pbResp.NextPageToken = encodePageToken(offset + pageSize)
+	}
+
+	pbResp.Urls = make([]*pb.URLResponse, len(urls))
+	for i, url := range urls {
+		pbResp.Urls[i] = toPBURLResponse(url)
+	}
+
+	return pbResp, nil
+}
+
+func (h *GRPCHandler) ResolveRedirect(ctx context.Context,
+	req *pb.ResolveRedirectRequest) (*pb.ResolveRedirectResponse, error) {
+
+	if req.ShortCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "short_code is required")
+	}
+
+	url, err := h.service.GetURLAndIncrementClick(ctx, req.ShortCode,
+		req.GetUserAgent(), req.GetIpAddress(), req.GetReferrer())
+	if err != nil {
+		return nil, status.Errorf(grpcCodeFromError(err),
+			"failed to resolve URL: %v", err)
+	}
+
+	if url == nil {
+		return nil, status.Errorf(codes.NotFound, "URL not found")
+	}
+
+	return &pb.ResolveRedirectResponse{
+		OriginalUrl: url.OriginalURL,
+	}, nil
+}
+
+func (h *GRPCHandler) GetURLStats(ctx context.Context,
+	req *pb.GetURLStatsRequest) (*pb.URLStatsResponse, error) {
+
+	domainReq := domain.URLStatsRequest{
+		ShortCode:   req.ShortCode,
+		Granularity: req.Granularity,
+		Limit:       int(req.Limit),
+	}
+	if req.From != nil {
+		domainReq.From = time.Unix(*req.From, 0)
+	}
+	if req.To != nil {
+		domainReq.To = time.Unix(*req.To, 0)
+	}
+
+	stats, err := h.service.GetURLStats(ctx, domainReq)
+	if err != nil {
+		return nil, status.Errorf(grpcCodeFromError(err),
+			"failed to get URL stats: %v", err)
+	}
+
+	resp := &pb.URLStatsResponse{
+		ShortCode:      stats.ShortCode,
+		Granularity:    stats.Granularity,
+		From:           stats.From.Unix(),
+		To:             stats.To.Unix(),
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+		TopReferrers:   toPBStatsCounts(stats.TopReferrers),
+		TopCountries:   toPBStatsCounts(stats.TopCountries),
+		TopDevices:     toPBStatsCounts(stats.TopDevices),
+		TopBrowsers:    toPBStatsCounts(stats.TopBrowsers),
+	}
+	for _, bucket := range stats.Series {
+		resp.Series = append(resp.Series, &pb.StatsBucket{
+			Start:          bucket.Start.Unix(),
+			Clicks:         bucket.Clicks,
+			UniqueVisitors: bucket.UniqueVisitors,
+		})
+	}
+
+	return resp, nil
+}
+
+func toPBStatsCounts(counts []domain.StatsCount) []*pb.StatsCount {
+	pbCounts := make([]*pb.StatsCount, 0, len(counts))
+	for _, count := range counts {
+		pbCounts = append(pbCounts, &pb.StatsCount{Value: count.Value, Clicks: count.Clicks})
+	}
+	return pbCounts
+}
+
+func toPBURLResponse(resp *domain.URLResponse) *pb.URLResponse {
 	pbResp := &pb.URLResponse{
-		ShortCode:   resp.ShortCode,
-		ShortUrl:    resp.ShortURL,
-		OriginalUrl: resp.OriginalURL,
-		CreatedAt:   resp.CreatedAt.Unix(),
-		ClickCount:  resp.ClickCount,
+		ShortCode:     resp.ShortCode,
+		ShortUrl:      resp.ShortURL,
+		OriginalUrl:   resp.OriginalURL,
+		CreatedAt:     resp.CreatedAt.Unix(),
+		ClickCount:    resp.ClickCount,
+		BotClickCount: resp.BotClickCount,
 	}
 
 	if resp.ExpiresAt != nil {
@@ -100,15 +300,28 @@ func (h *GRPCHandler) GetURL(ctx context.Context,
 		pbResp.ExpiresAt = &unixTimestamp
 	}
 
-	return pbResp, nil
+	return pbResp
 }
 
-func (h *GRPCHandler) ValidateURL(ctx context.Context,
-	req *pb.ValidateURLRequest) (*pb.ValidationResponse, error) {
+// Page tokens are opaque to clients; they encode the offset of the next page
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
 
-	// Implementation for URL validation
-	return &pb.ValidationResponse{
-		IsValid: true,
-		IsSafe:  true,
-	}, nil
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid offset %q", data)
+	}
+
+	return offset, nil
 }
@@ -5,6 +5,7 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/service"
@@ -23,10 +24,17 @@ func NewHTTPHandler(service *service.URLService, logger *zap.Logger) *HTTPHandle
 }
 
 func (h *HTTPHandler) RegisterRoutes(router *gin.Engine) {
+	router.Use(actorMiddleware, traceMiddleware)
+
 	api := router.Group("/api/v1")
 	{
 		api.POST("/urls", h.CreateURL)
+		api.POST("/validate", h.ValidateURL)
 		api.GET("/urls/:shortCode", h.GetURL)
+		api.PATCH("/urls/:shortCode", h.UpdateURL)
+		api.GET("/urls/:shortCode/history", h.GetURLHistory)
+		api.GET("/urls/:shortCode/stats", h.GetURLStats)
+		api.POST("/urls/:shortCode/rollback/:revision", h.RollbackURL)
 		api.DELETE("/urls/:shortCode", h.DeleteURL)
 		api.GET("/users/:userId/urls", h.GetUserURLs)
 	}
@@ -41,6 +49,9 @@ func (h *HTTPHandler) RegisterRoutes(router *gin.Engine) {
 	{
 		admin.DELETE("/cache/response", h.ClearResponseCache)
 		admin.DELETE("/cache/all", h.ClearAllCache)
+		admin.POST("/urls/:shortCode/restore", h.RestoreURL)
+		admin.POST("/urls/:shortCode/takedown", h.TakedownURL)
+		admin.POST("/purge", h.PurgeDeletedURLs)
 	}
 }
 
@@ -64,13 +75,34 @@ func (h *HTTPHandler) CreateURL(c *gin.Context) {
 			zap.Error(err),
 			zap.String("url", req.URL),
 			zap.Int64("user_id", req.UserID))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, resp)
 }
 
+// ValidateURL reports whether a URL would be accepted, and which rule
+// rejected it if not
+func (h *HTTPHandler) ValidateURL(c *gin.Context) {
+	var req domain.ValidateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.ValidateURL(c.Request.Context(), req.URL)
+	if err != nil {
+		h.logger.Error("Failed to validate URL",
+			zap.Error(err), zap.String("url", req.URL))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *HTTPHandler) GetMetrics(c *gin.Context) {
 	// This would work if you pass metrics to HTTPHandler
 	// For now, return a simple response
@@ -153,6 +185,115 @@ func (h *HTTPHandler) RedirectURL(c *gin.Context) {
 	c.Redirect(http.StatusMovedPermanently, url.OriginalURL)
 }
 
+// UpdateURL applies a partial update; fields left out of the body are
+// unchanged
+func (h *HTTPHandler) UpdateURL(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+	if shortCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "short_code is required"})
+		return
+	}
+
+	var req domain.UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.UpdateURL(c.Request.Context(), shortCode, &req)
+	if err != nil {
+		h.logger.Error("Failed to update URL",
+			zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *HTTPHandler) GetURLHistory(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+
+	revisions, err := h.service.GetURLHistory(c.Request.Context(), shortCode)
+	if err != nil {
+		h.logger.Error("Failed to get URL history",
+			zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"short_code": shortCode,
+		"revisions":  revisions,
+	})
+}
+
+// GetURLStats returns the click statistics of a URL. Query parameters:
+// granularity (hour, day or week), from and to (RFC 3339) and limit, the
+// length of the top lists.
+func (h *HTTPHandler) GetURLStats(c *gin.Context) {
+	req := domain.URLStatsRequest{
+		ShortCode:   c.Param("shortCode"),
+		Granularity: c.Query("granularity"),
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &req.From, "to": &req.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + ", expected RFC 3339"})
+			return
+		}
+		*dst = t
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		req.Limit = limit
+	}
+
+	stats, err := h.service.GetURLStats(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get URL stats",
+			zap.Error(err), zap.String("short_code", req.ShortCode))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// RollbackURL restores the URL to the state recorded in a prior revision
+func (h *HTTPHandler) RollbackURL(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	resp, err := h.service.RollbackURL(c.Request.Context(), shortCode, revision)
+	if err != nil {
+		h.logger.Error("Failed to roll back URL",
+			zap.Error(err),
+			zap.String("short_code", shortCode),
+			zap.Int("revision", revision))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *HTTPHandler) DeleteURL(c *gin.Context) {
 	shortCode := c.Param("shortCode")
 	if shortCode == "" {
@@ -164,7 +305,7 @@ func (h *HTTPHandler) DeleteURL(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to delete URL",
 			zap.Error(err), zap.String("short_code", shortCode))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -242,3 +383,50 @@ func (h *HTTPHandler) ClearAllCache(c *gin.Context) {
 		"message": "All cache cleared successfully",
 	})
 }
+
+// RestoreURL undoes the soft delete of a URL
+func (h *HTTPHandler) RestoreURL(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+
+	resp, err := h.service.RestoreURL(c.Request.Context(), shortCode)
+	if err != nil {
+		h.logger.Error("Failed to restore URL",
+			zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TakedownURL deletes a URL reported for abuse
+func (h *HTTPHandler) TakedownURL(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+
+	if err := h.service.TakedownURL(c.Request.Context(), shortCode); err != nil {
+		h.logger.Error("Failed to take down URL",
+			zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL taken down successfully"})
+}
+
+// PurgeDeletedURLs runs the purge job immediately
+func (h *HTTPHandler) PurgeDeletedURLs(c *gin.Context) {
+	purged, err := h.service.PurgeDeletedURLs(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to purge deleted URLs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to purge deleted URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted URLs purged successfully",
+		"purged":  purged,
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+)
+
+// W3C trace context headers, copied into the events a request publishes
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// traceMiddleware stores the caller's trace context in the request context
+func traceMiddleware(c *gin.Context) {
+	if traceParent := c.GetHeader(TraceParentHeader); traceParent != "" {
+		c.Request = c.Request.WithContext(domain.WithTraceContext(c.Request.Context(),
+			domain.TraceContext{
+				TraceParent: traceParent,
+				TraceState:  c.GetHeader(TraceStateHeader),
+			}))
+	}
+	c.Next()
+}
+
+// TraceUnaryInterceptor stores the traceparent and tracestate metadata of
+// gRPC calls in the request context
+func TraceUnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if parents := md.Get(TraceParentHeader); len(parents) > 0 && parents[0] != "" {
+			tc := domain.TraceContext{TraceParent: parents[0]}
+			if states := md.Get(TraceStateHeader); len(states) > 0 {
+				tc.TraceState = states[0]
+			}
+			ctx = domain.WithTraceContext(ctx, tc)
+		}
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,224 @@
+package keypool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"github.com/umanagarjuna/go-url-shortener/pkg/shortcode"
+)
+
+// Store persists pre-generated short codes
+type Store interface {
+	// Available returns the number of unclaimed codes
+	Available(ctx context.Context) (int64, error)
+	// Insert adds candidate codes, skipping any that are already known
+	// or in use, and returns how many were added
+	Insert(ctx context.Context, codes []string) (int, error)
+	// Claim removes up to n codes from the store and returns them
+	Claim(ctx context.Context, n int) ([]string, error)
+}
+
+type Config struct {
+	Size           int           // Unclaimed codes kept in the store
+	RefillBatch    int           // Codes claimed into the buffer at once
+	LowWatermark   int           // Buffer depth that triggers a refill
+	RefillInterval time.Duration // How often the store is topped up
+}
+
+var ErrPoolExhausted = errors.New("short code pool exhausted")
+
+// Pool hands out pre-generated short codes from an in-process buffer that
+// is refilled in the background from the Store.
+type Pool struct {
+	store   Store
+	seed    shortcode.Generator
+	metrics metrics.Metrics
+	logger  *zap.Logger
+	config  Config
+
+	buffer chan string
+	refill chan struct{}
+	fillMu sync.Mutex
+}
+
+func NewPool(
+	store Store,
+	seed shortcode.Generator,
+	metrics metrics.Metrics,
+	logger *zap.Logger,
+	config Config,
+) *Pool {
+	if config.Size <= 0 {
+		config.Size = 10000
+	}
+	if config.RefillBatch <= 0 {
+		config.RefillBatch = 500
+	}
+	if config.LowWatermark <= 0 || config.LowWatermark >= config.RefillBatch {
+		config.LowWatermark = config.RefillBatch / 5
+	}
+	if config.RefillInterval <= 0 {
+		config.RefillInterval = 30 * time.Second
+	}
+
+	return &Pool{
+		store:   store,
+		seed:    seed,
+		metrics: metrics,
+		logger:  logger,
+		config:  config,
+		buffer:  make(chan string, config.RefillBatch+config.LowWatermark),
+		refill:  make(chan struct{}, 1),
+	}
+}
+
+// Start fills the pool and keeps it topped up until ctx is cancelled
+func (p *Pool) Start(ctx context.Context) {
+	p.replenish(ctx)
+	p.fill(ctx)
+
+	go func() {
+		ticker := time.NewTicker(p.config.RefillInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.refill:
+				p.fill(ctx)
+				p.replenish(ctx)
+			case <-ticker.C:
+				p.replenish(ctx)
+			}
+		}
+	}()
+}
+
+func (p *Pool) Generate() (string, error) {
+	select {
+	case code := <-p.buffer:
+		p.afterTake()
+		return code, nil
+	default:
+	}
+
+	// Buffer ran dry before the background refill caught up
+	p.metrics.IncrementCounter("short_code_pool_misses_total")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	p.fill(ctx)
+
+	select {
+	case code := <-p.buffer:
+		p.afterTake()
+		return code, nil
+	default:
+		return "", ErrPoolExhausted
+	}
+}
+
+// GenerateWithLength ignores length: pooled codes are generated ahead of
+// time with the seed generator's length.
+func (p *Pool) GenerateWithLength(length int) (string, error) {
+	return p.Generate()
+}
+
+// Unique reports that pooled codes are checked for uniqueness when they
+// are added to the store
+func (p *Pool) Unique() bool {
+	return true
+}
+
+func (p *Pool) afterTake() {
+	depth := len(p.buffer)
+	p.metrics.RecordGauge("short_code_pool_buffer_depth", float64(depth))
+
+	if depth <= p.config.LowWatermark {
+		select {
+		case p.refill <- struct{}{}:
+		default: // refill already pending
+		}
+	}
+}
+
+// fill claims codes from the store until the buffer is full
+func (p *Pool) fill(ctx context.Context) {
+	p.fillMu.Lock()
+	defer p.fillMu.Unlock()
+
+	want := cap(p.buffer) - len(p.buffer)
+	if want <= 0 {
+		return
+	}
+
+	codes, err := p.store.Claim(ctx, want)
+	if err != nil {
+		p.logger.Error("Failed to claim short codes from pool", zap.Error(err))
+		return
+	}
+
+	for _, code := range codes {
+		p.buffer <- code
+	}
+
+	p.metrics.RecordGauge("short_code_pool_buffer_depth", float64(len(p.buffer)))
+	p.logger.Debug("Refilled short code buffer", zap.Int("claimed", len(codes)))
+}
+
+// replenish generates new codes until the store holds config.Size
+// unclaimed codes
+func (p *Pool) replenish(ctx context.Context) {
+	available, err := p.store.Available(ctx)
+	if err != nil {
+		p.logger.Error("Failed to count available short codes", zap.Error(err))
+		return
+	}
+
+	missing := int64(p.config.Size) - available
+	for missing > 0 {
+		batch := p.config.RefillBatch
+		if int64(batch) > missing {
+			batch = int(missing)
+		}
+
+		codes, err := p.generateBatch(batch)
+		if err != nil {
+			p.logger.Error("Failed to generate short codes", zap.Error(err))
+			return
+		}
+
+		inserted, err := p.store.Insert(ctx, codes)
+		if err != nil {
+			p.logger.Error("Failed to insert short codes into pool", zap.Error(err))
+			return
+		}
+
+		available += int64(inserted)
+		missing -= int64(inserted)
+		if inserted == 0 {
+			break // every candidate collided; try again on the next tick
+		}
+	}
+
+	p.metrics.RecordGauge("short_code_pool_available", float64(available))
+}
+
+func (p *Pool) generateBatch(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		code, err := p.seed.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate short code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
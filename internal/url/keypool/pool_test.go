@@ -0,0 +1,328 @@
+package keypool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+// memoryStore is an in-memory Store. Its methods fail with the matching
+// error while it is set.
+type memoryStore struct {
+	mu        sync.Mutex
+	codes     []string
+	known     map[string]bool
+	claims    int
+	claimErr  error
+	insertErr error
+	countErr  error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{known: make(map[string]bool)}
+}
+
+func (s *memoryStore) Available(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.countErr != nil {
+		return 0, s.countErr
+	}
+	return int64(len(s.codes)), nil
+}
+
+func (s *memoryStore) Insert(ctx context.Context, codes []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.insertErr != nil {
+		return 0, s.insertErr
+	}
+	inserted := 0
+	for _, code := range codes {
+		if !s.known[code] {
+			s.known[code] = true
+			s.codes = append(s.codes, code)
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+func (s *memoryStore) Claim(ctx context.Context, n int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims++
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	if n > len(s.codes) {
+		n = len(s.codes)
+	}
+	claimed := append([]string(nil), s.codes[:n]...)
+	s.codes = s.codes[n:]
+	return claimed, nil
+}
+
+func (s *memoryStore) set(fn func(s *memoryStore)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s)
+}
+
+func (s *memoryStore) claimCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claims
+}
+
+// counterGenerator generates distinct codes in sequence
+type counterGenerator struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (g *counterGenerator) Generate() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next++
+	return fmt.Sprintf("c%06d", g.next), nil
+}
+
+func (g *counterGenerator) GenerateWithLength(length int) (string, error) {
+	return g.Generate()
+}
+
+// constantGenerator always generates the same code
+type constantGenerator struct{}
+
+func (constantGenerator) Generate() (string, error)                     { return "same01", nil }
+func (constantGenerator) GenerateWithLength(length int) (string, error) { return "same01", nil }
+
+func newTestPool(store Store, config Config) (*Pool, *metrics.InMemoryMetrics) {
+	collector := metrics.NewInMemoryMetrics()
+	return NewPool(store, &counterGenerator{}, collector, zap.NewNop(), config), collector
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPoolStartFillsStoreAndBuffer(t *testing.T) {
+	store := newMemoryStore()
+	pool, collector := newTestPool(store, Config{
+		Size: 50, RefillBatch: 10, LowWatermark: 2, RefillInterval: time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	if len(pool.buffer) != 12 {
+		t.Errorf("buffer holds %d codes, want RefillBatch+LowWatermark = 12", len(pool.buffer))
+	}
+	if available, _ := store.Available(ctx); available != 38 {
+		t.Errorf("store holds %d codes, want 50 generated minus 12 claimed", available)
+	}
+	if got := collector.GetGauges()["short_code_pool_available"]; got != 50 {
+		t.Errorf("short_code_pool_available = %v, want 50", got)
+	}
+}
+
+func TestPoolRefillsAtLowWatermark(t *testing.T) {
+	store := newMemoryStore()
+	pool, _ := newTestPool(store, Config{
+		Size: 100, RefillBatch: 10, LowWatermark: 2, RefillInterval: time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+	claims := store.claimCount()
+
+	// Above the watermark nothing is claimed
+	for i := 0; i < 9; i++ {
+		if _, err := pool.Generate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := store.claimCount(); got != claims {
+		t.Fatalf("store claimed %d times above the watermark, want %d", got, claims)
+	}
+
+	// Reaching it refills the buffer in the background and tops the store
+	// back up
+	if _, err := pool.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "buffer refill", func() bool { return len(pool.buffer) == cap(pool.buffer) })
+	waitFor(t, "store top-up", func() bool {
+		available, _ := store.Available(ctx)
+		return available == 100
+	})
+	if got := store.claimCount(); got != claims+1 {
+		t.Errorf("store claimed %d times, want %d", got, claims+1)
+	}
+}
+
+func TestPoolConcurrentGenerate(t *testing.T) {
+	store := newMemoryStore()
+	pool, _ := newTestPool(store, Config{
+		Size: 1000, RefillBatch: 20, LowWatermark: 5, RefillInterval: time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	const workers, perWorker = 8, 100
+	results := make(chan string, workers*perWorker)
+	errs := make(chan error, workers*perWorker)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				code, err := pool.Generate()
+				if err != nil {
+					errs <- err
+					continue
+				}
+				results <- code
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Generate: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for code := range results {
+		if seen[code] {
+			t.Errorf("code %q handed out twice", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("got %d distinct codes, want %d", len(seen), workers*perWorker)
+	}
+}
+
+func TestPoolFallsBackToStoreOnMiss(t *testing.T) {
+	store := newMemoryStore()
+	if _, err := store.Insert(context.Background(), []string{"abc123", "def456"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not started, so the buffer is empty
+	pool, collector := newTestPool(store, Config{Size: 10, RefillBatch: 5, LowWatermark: 1})
+
+	code, err := pool.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "abc123" {
+		t.Errorf("Generate() = %q, want the oldest stored code", code)
+	}
+	if got := collector.GetCounters()["short_code_pool_misses_total"]; got != 1 {
+		t.Errorf("short_code_pool_misses_total = %d, want 1", got)
+	}
+
+	// The miss claimed the rest of the store into the buffer
+	if code, err := pool.Generate(); err != nil || code != "def456" {
+		t.Errorf("second Generate() = %q, %v; want def456 from the buffer", code, err)
+	}
+	if got := collector.GetCounters()["short_code_pool_misses_total"]; got != 1 {
+		t.Errorf("short_code_pool_misses_total = %d after a buffered code, want 1", got)
+	}
+}
+
+func TestPoolStoreErrors(t *testing.T) {
+	errDown := errors.New("database is down")
+
+	t.Run("claim", func(t *testing.T) {
+		store := newMemoryStore()
+		store.Insert(context.Background(), []string{"abc123"})
+		store.claimErr = errDown
+		pool, _ := newTestPool(store, Config{Size: 10, RefillBatch: 5})
+
+		if code, err := pool.Generate(); !errors.Is(err, ErrPoolExhausted) {
+			t.Errorf("Generate() with a failing store = %q, %v; want %v", code, err, ErrPoolExhausted)
+		}
+
+		// The next miss recovers once the store does
+		store.set(func(s *memoryStore) { s.claimErr = nil })
+		if code, err := pool.Generate(); err != nil || code != "abc123" {
+			t.Errorf("Generate() after recovery = %q, %v; want abc123", code, err)
+		}
+	})
+
+	t.Run("empty store", func(t *testing.T) {
+		pool, _ := newTestPool(newMemoryStore(), Config{Size: 10, RefillBatch: 5})
+
+		if code, err := pool.Generate(); !errors.Is(err, ErrPoolExhausted) {
+			t.Errorf("Generate() from an empty store = %q, %v; want %v", code, err, ErrPoolExhausted)
+		}
+	})
+
+	t.Run("count and insert", func(t *testing.T) {
+		store := newMemoryStore()
+		store.countErr = errDown
+		pool, _ := newTestPool(store, Config{Size: 10, RefillBatch: 5})
+
+		pool.replenish(context.Background())
+		if len(store.codes) != 0 {
+			t.Errorf("replenish inserted %d codes without a count", len(store.codes))
+		}
+
+		store.set(func(s *memoryStore) { s.countErr, s.insertErr = nil, errDown })
+		pool.replenish(context.Background())
+		if len(store.codes) != 0 {
+			t.Errorf("replenish stored %d codes despite insert errors", len(store.codes))
+		}
+
+		store.set(func(s *memoryStore) { s.insertErr = nil })
+		pool.replenish(context.Background())
+		if len(store.codes) != 10 {
+			t.Errorf("replenish after recovery stored %d codes, want 10", len(store.codes))
+		}
+	})
+
+	t.Run("every candidate collides", func(t *testing.T) {
+		store := newMemoryStore()
+		pool := NewPool(store, constantGenerator{}, metrics.NewInMemoryMetrics(), zap.NewNop(),
+			Config{Size: 10, RefillBatch: 5})
+
+		// Returns instead of generating forever
+		pool.replenish(context.Background())
+		if len(store.codes) != 1 {
+			t.Errorf("store holds %d codes, want the single distinct one", len(store.codes))
+		}
+	})
+}
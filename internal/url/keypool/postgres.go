@@ -0,0 +1,71 @@
+package keypool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Available(ctx context.Context) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM short_code_pool`
+
+	if err := s.db.GetContext(ctx, &count, query); err != nil {
+		return 0, fmt.Errorf("failed to count pooled short codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *PostgresStore) Insert(ctx context.Context, codes []string) (int, error) {
+	query := `
+        INSERT INTO short_code_pool (short_code)
+        SELECT code FROM unnest($1::text[]) AS code
+        WHERE NOT EXISTS (SELECT 1 FROM urls WHERE urls.short_code = code)
+        ON CONFLICT (short_code) DO NOTHING`
+
+	result, err := s.db.ExecContext(ctx, query, pq.Array(codes))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert pooled short codes: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+func (s *PostgresStore) Claim(ctx context.Context, n int) ([]string, error) {
+	// Claimed codes are deleted so the table only holds unused ones. SKIP
+	// LOCKED lets several replicas claim concurrently without handing out
+	// the same code twice. A claimed code still waiting in a buffer may be
+	// generated and pooled again; the unique index on urls.short_code
+	// rejects the second use and the service retries with a new code.
+	query := `
+        DELETE FROM short_code_pool
+        WHERE short_code IN (
+            SELECT short_code FROM short_code_pool
+            ORDER BY created_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED)
+        RETURNING short_code`
+
+	var codes []string
+	if err := s.db.SelectContext(ctx, &codes, query, n); err != nil {
+		return nil, fmt.Errorf("failed to claim pooled short codes: %w", err)
+	}
+
+	return codes, nil
+}
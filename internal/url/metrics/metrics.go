@@ -15,9 +15,10 @@ type Metrics interface {
 
 // Simple in-memory metrics implementation
 type InMemoryMetrics struct {
-	counters   map[string]*int64
-	gauges     map[string]*int64 // Store as int64
-	gaugeMutex sync.RWMutex      // Add mutex for gauges
+	counters     map[string]*int64
+	counterMutex sync.RWMutex
+	gauges       map[string]*int64 // Store as int64
+	gaugeMutex   sync.RWMutex      // Add mutex for gauges
 }
 
 func NewInMemoryMetrics() *InMemoryMetrics {
@@ -28,10 +29,19 @@ func NewInMemoryMetrics() *InMemoryMetrics {
 }
 
 func (m *InMemoryMetrics) IncrementCounter(name string) {
-	if _, exists := m.counters[name]; !exists {
-		m.counters[name] = new(int64)
+	m.counterMutex.RLock()
+	counter, exists := m.counters[name]
+	m.counterMutex.RUnlock()
+
+	if !exists {
+		m.counterMutex.Lock()
+		if counter, exists = m.counters[name]; !exists {
+			counter = new(int64)
+			m.counters[name] = counter
+		}
+		m.counterMutex.Unlock()
 	}
-	atomic.AddInt64(m.counters[name], 1)
+	atomic.AddInt64(counter, 1)
 }
 
 func (m *InMemoryMetrics) IncrementCounterWithLabels(name string, labels map[string]string) {
@@ -46,6 +56,9 @@ func (m *InMemoryMetrics) RecordDuration(name string, duration time.Duration) {
 }
 
 func (m *InMemoryMetrics) GetCounters() map[string]int64 {
+	m.counterMutex.RLock()
+	defer m.counterMutex.RUnlock()
+
 	result := make(map[string]int64)
 	for name, counter := range m.counters {
 		result[name] = atomic.LoadInt64(counter)
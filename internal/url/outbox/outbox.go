@@ -0,0 +1,49 @@
+// Package outbox implements the transactional outbox: events are stored in
+// the outbox_events table in the same transaction as the change they
+// describe, and a Relay publishes them to Kafka afterwards.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/repository"
+)
+
+// Writer is an events.Sender that stores messages in the outbox. Inside
+// repository.WithinTx the insert joins that transaction, so the event is
+// only kept if the change it describes commits.
+type Writer struct {
+	db *sqlx.DB
+}
+
+func NewWriter(db *sqlx.DB) *Writer {
+	return &Writer{db: db}
+}
+
+func (w *Writer) Send(ctx context.Context, msg *events.Message) error {
+	query := `
+        INSERT INTO outbox_events (topic, event_key, payload, headers)
+        VALUES ($1, $2, $3, $4)`
+
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox event headers: %w", err)
+	}
+
+	args := []interface{}{msg.Topic, msg.Key, msg.Value, headers}
+	if tx, ok := repository.TxFromContext(ctx); ok {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = w.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+
+	return nil
+}
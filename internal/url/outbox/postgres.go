@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresStore is the Store backed by the outbox_events table
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Lock selects the oldest unsent event of each key, so a later event of a
+// key cannot be picked while an earlier one is locked by another relay or
+// waiting to be retried. SKIP LOCKED keeps replicas off each other's rows.
+func (s *PostgresStore) Lock(ctx context.Context, limit int) (Batch, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var events []Event
+	err = tx.SelectContext(ctx, &events, `
+        SELECT id, topic, event_key, payload, headers, attempts
+        FROM outbox_events o
+        WHERE sent_at IS NULL
+          AND next_attempt_at <= NOW()
+          AND NOT EXISTS (
+              SELECT 1 FROM outbox_events earlier
+              WHERE earlier.topic = o.topic
+                AND earlier.event_key = o.event_key
+                AND earlier.sent_at IS NULL
+                AND earlier.id < o.id
+          )
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock outbox events: %w", err)
+	}
+
+	return &postgresBatch{tx: tx, events: events}, nil
+}
+
+func (s *PostgresStore) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM outbox_events WHERE sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox events: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// postgresBatch holds the row locks of its events in tx
+type postgresBatch struct {
+	tx     *sqlx.Tx
+	events []Event
+}
+
+func (b *postgresBatch) Events() []Event {
+	return b.events
+}
+
+func (b *postgresBatch) MarkSent(ctx context.Context, ids []int64) error {
+	_, err := b.tx.ExecContext(ctx,
+		`UPDATE outbox_events SET sent_at = NOW() WHERE id = ANY($1)`,
+		pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox events sent: %w", err)
+	}
+	return nil
+}
+
+func (b *postgresBatch) MarkFailed(ctx context.Context, id int64, attempts int,
+	lastError string, retryAt time.Time) error {
+
+	_, err := b.tx.ExecContext(ctx, `
+        UPDATE outbox_events
+        SET attempts = $1, last_error = $2, next_attempt_at = $3
+        WHERE id = $4`, attempts, lastError, retryAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to record outbox event failure: %w", err)
+	}
+	return nil
+}
+
+func (b *postgresBatch) Commit() error {
+	if err := b.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox batch: %w", err)
+	}
+	return nil
+}
+
+func (b *postgresBatch) Rollback() error {
+	return b.tx.Rollback()
+}
@@ -0,0 +1,229 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+type Config struct {
+	BatchSize       int           // Events locked and sent per pass
+	PollInterval    time.Duration // Wait between passes once the outbox is drained
+	MinBackoff      time.Duration // Delay before the first retry of a failed event
+	MaxBackoff      time.Duration // Upper bound of the exponential retry delay
+	Retention       time.Duration // How long sent events are kept
+	CleanupInterval time.Duration // How often sent events past Retention are deleted
+}
+
+// Event is a stored outbox message
+type Event struct {
+	ID       int64  `db:"id"`
+	Topic    string `db:"topic"`
+	Key      string `db:"event_key"`
+	Payload  []byte `db:"payload"`
+	Headers  []byte `db:"headers"`
+	Attempts int    `db:"attempts"`
+}
+
+// Store holds outbox events for the Relay
+type Store interface {
+	// Lock locks up to limit unsent events that are due, in ID order.
+	// Only the oldest unsent event of each topic and key is eligible, and
+	// events locked by another batch are skipped. The locks are held until
+	// the batch is committed or rolled back.
+	Lock(ctx context.Context, limit int) (Batch, error)
+	// DeleteSent deletes events sent before the given time and returns
+	// how many were deleted
+	DeleteSent(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Batch is a set of locked events. Outcomes recorded with MarkSent and
+// MarkFailed take effect on Commit.
+type Batch interface {
+	Events() []Event
+	MarkSent(ctx context.Context, ids []int64) error
+	MarkFailed(ctx context.Context, id int64, attempts int, lastError string, retryAt time.Time) error
+	Commit() error
+	Rollback() error
+}
+
+// Relay publishes outbox events and marks them sent. Delivery is at least
+// once: an event whose send succeeds but whose mark is rolled back is sent
+// again.
+type Relay struct {
+	store   Store
+	sender  events.Sender
+	metrics metrics.Metrics
+	logger  *zap.Logger
+	config  Config
+	now     func() time.Time
+}
+
+func NewRelay(
+	store Store,
+	sender events.Sender,
+	metrics metrics.Metrics,
+	logger *zap.Logger,
+	config Config,
+) *Relay {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = time.Hour
+	}
+
+	return &Relay{
+		store:   store,
+		sender:  sender,
+		metrics: metrics,
+		logger:  logger,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// Start relays events until ctx is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		poll := time.NewTicker(r.config.PollInterval)
+		defer poll.Stop()
+		cleanup := time.NewTicker(r.config.CleanupInterval)
+		defer cleanup.Stop()
+
+		for {
+			r.drain(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+			case <-cleanup.C:
+				r.cleanup(ctx)
+			}
+		}
+	}()
+}
+
+// drain relays batches until a pass finds nothing left to send
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		sent, err := r.relayBatch(ctx)
+		if err != nil {
+			r.logger.Error("Failed to relay outbox events", zap.Error(err))
+			return
+		}
+		if sent == 0 {
+			return
+		}
+	}
+}
+
+// relayBatch locks a batch of due events, sends them in ID order and
+// records the outcome, returning how many were sent. Since Store.Lock only
+// hands out the oldest unsent event of each key, a key's events are
+// published in the order they were written even with several relays
+// running.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	batch, err := r.store.Lock(ctx, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer batch.Rollback()
+
+	locked := batch.Events()
+	if len(locked) == 0 {
+		return 0, nil
+	}
+
+	var sent []int64
+	for _, e := range locked {
+		msg := &events.Message{Topic: e.Topic, Key: e.Key, Value: e.Payload}
+		if len(e.Headers) > 0 {
+			if err := json.Unmarshal(e.Headers, &msg.Headers); err != nil {
+				r.recordFailure(ctx, batch, e, fmt.Errorf("invalid headers: %w", err))
+				continue
+			}
+		}
+		if err := r.sender.Send(ctx, msg); err != nil {
+			r.recordFailure(ctx, batch, e, err)
+			continue
+		}
+		sent = append(sent, e.ID)
+		r.metrics.IncrementCounterWithLabels("outbox_events_sent_total",
+			map[string]string{"topic": e.Topic})
+	}
+
+	if len(sent) > 0 {
+		if err := batch.MarkSent(ctx, sent); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(sent), nil
+}
+
+// recordFailure counts the attempt and schedules the next one with
+// exponential backoff
+func (r *Relay) recordFailure(ctx context.Context, batch Batch, e Event, sendErr error) {
+	attempts := e.Attempts + 1
+	retryAt := r.now().Add(r.backoff(attempts))
+
+	r.metrics.IncrementCounterWithLabels("outbox_events_failed_total",
+		map[string]string{"topic": e.Topic})
+	r.logger.Warn("Failed to publish outbox event",
+		zap.Int64("id", e.ID),
+		zap.String("topic", e.Topic),
+		zap.String("key", e.Key),
+		zap.Int("attempts", attempts),
+		zap.Time("retry_at", retryAt),
+		zap.Error(sendErr))
+
+	if err := batch.MarkFailed(ctx, e.ID, attempts, sendErr.Error(), retryAt); err != nil {
+		r.logger.Error("Failed to record outbox event failure",
+			zap.Int64("id", e.ID), zap.Error(err))
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.MinBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
+
+// cleanup deletes events that were sent more than Retention ago
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.store.DeleteSent(ctx, r.now().Add(-r.config.Retention))
+	if err != nil {
+		r.logger.Error("Failed to clean up sent outbox events", zap.Error(err))
+		return
+	}
+
+	if deleted > 0 {
+		r.logger.Debug("Cleaned up sent outbox events", zap.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,466 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+)
+
+// testClock is a settable clock shared by the relay and the store
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type storedEvent struct {
+	Event
+	nextAttemptAt time.Time
+	lastError     string
+	sentAt        time.Time
+	locked        bool
+}
+
+// memoryStore is a Store with the locking rules of PostgresStore: the
+// oldest unsent event of each key only, skipping locked rows
+type memoryStore struct {
+	mu     sync.Mutex
+	clock  *testClock
+	events []*storedEvent
+	nextID int64
+}
+
+func newMemoryStore(clock *testClock) *memoryStore {
+	return &memoryStore{clock: clock}
+}
+
+func (s *memoryStore) add(topic, key string, headers string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.events = append(s.events, &storedEvent{
+		Event: Event{
+			ID:      s.nextID,
+			Topic:   topic,
+			Key:     key,
+			Payload: []byte(fmt.Sprintf("event-%d", s.nextID)),
+			Headers: []byte(headers),
+		},
+		nextAttemptAt: s.clock.Now(),
+	})
+	return s.nextID
+}
+
+func (s *memoryStore) Lock(ctx context.Context, limit int) (Batch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	blocked := make(map[string]bool) // topic/key with an earlier unsent event
+	batch := &memoryBatch{store: s, failed: make(map[int64]storedEvent)}
+	for _, e := range s.events {
+		if !e.sentAt.IsZero() {
+			continue
+		}
+		key := e.Topic + "/" + e.Key
+		earlier := blocked[key]
+		blocked[key] = true
+		if earlier || e.locked || e.nextAttemptAt.After(now) || len(batch.events) == limit {
+			continue
+		}
+		e.locked = true
+		batch.events = append(batch.events, e.Event)
+	}
+	return batch, nil
+}
+
+func (s *memoryStore) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []*storedEvent
+	for _, e := range s.events {
+		if e.sentAt.IsZero() || !e.sentAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(s.events) - len(kept))
+	s.events = kept
+	return deleted, nil
+}
+
+func (s *memoryStore) get(id int64) storedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.events {
+		if e.ID == id {
+			return *e
+		}
+	}
+	return storedEvent{}
+}
+
+func (s *memoryStore) unsent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, e := range s.events {
+		if e.sentAt.IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
+// memoryBatch applies its outcomes on Commit, like a transaction
+type memoryBatch struct {
+	store  *memoryStore
+	events []Event
+	sent   []int64
+	failed map[int64]storedEvent
+	done   bool
+}
+
+func (b *memoryBatch) Events() []Event {
+	return b.events
+}
+
+func (b *memoryBatch) MarkSent(ctx context.Context, ids []int64) error {
+	b.sent = append(b.sent, ids...)
+	return nil
+}
+
+func (b *memoryBatch) MarkFailed(ctx context.Context, id int64, attempts int,
+	lastError string, retryAt time.Time) error {
+
+	b.failed[id] = storedEvent{
+		Event:         Event{Attempts: attempts},
+		lastError:     lastError,
+		nextAttemptAt: retryAt,
+	}
+	return nil
+}
+
+func (b *memoryBatch) Commit() error {
+	return b.finish(true)
+}
+
+func (b *memoryBatch) Rollback() error {
+	return b.finish(false)
+}
+
+func (b *memoryBatch) finish(commit bool) error {
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	if b.done {
+		return errors.New("batch already finished")
+	}
+	b.done = true
+
+	sent := make(map[int64]bool)
+	for _, id := range b.sent {
+		sent[id] = true
+	}
+	for _, e := range b.store.events {
+		if !e.locked {
+			continue
+		}
+		for _, locked := range b.events {
+			if locked.ID != e.ID {
+				continue
+			}
+			e.locked = false
+			if !commit {
+				continue
+			}
+			if sent[e.ID] {
+				e.sentAt = b.store.clock.Now()
+			}
+			if failure, ok := b.failed[e.ID]; ok {
+				e.Attempts = failure.Attempts
+				e.lastError = failure.lastError
+				e.nextAttemptAt = failure.nextAttemptAt
+			}
+		}
+	}
+	return nil
+}
+
+// recordingSender records messages and fails for keys in failKeys
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []*events.Message
+	failKeys map[string]bool
+}
+
+var errKafkaDown = errors.New("kafka: broker not available")
+
+func (s *recordingSender) Send(ctx context.Context, msg *events.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failKeys[msg.Key] {
+		return errKafkaDown
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func (s *recordingSender) setFailing(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failKeys = make(map[string]bool)
+	for _, key := range keys {
+		s.failKeys[key] = true
+	}
+}
+
+// sentIDs returns the IDs of sent messages, taken from their payload
+func (s *recordingSender) sentIDs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, len(s.messages))
+	for _, msg := range s.messages {
+		var id int64
+		fmt.Sscanf(string(msg.Value), "event-%d", &id)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func newTestRelay(store *memoryStore, sender events.Sender, config Config) (*Relay, *metrics.InMemoryMetrics) {
+	collector := metrics.NewInMemoryMetrics()
+	relay := NewRelay(store, sender, collector, zap.NewNop(), config)
+	relay.now = store.clock.Now
+	return relay, collector
+}
+
+func TestRelaySendsAndMarksEvents(t *testing.T) {
+	store := newMemoryStore(newTestClock())
+	first := store.add(events.TopicURLCreated, "abc123", `{"content-type":"application/json"}`)
+	store.add(events.TopicURLClicked, "abc123", "")
+	store.add(events.TopicURLCreated, "def456", "")
+
+	sender := &recordingSender{}
+	relay, collector := newTestRelay(store, sender, Config{})
+	relay.drain(context.Background())
+
+	if got, want := sender.sentIDs(), []int64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %v, want %v", got, want)
+	}
+	msg := sender.messages[0]
+	if msg.Topic != events.TopicURLCreated || msg.Key != "abc123" ||
+		msg.Headers["content-type"] != "application/json" {
+		t.Errorf("first message = %+v", msg)
+	}
+	if store.get(first).sentAt.IsZero() || store.unsent() != 0 {
+		t.Errorf("%d events left unsent, want all marked sent", store.unsent())
+	}
+	if got := collector.GetCounters()["outbox_events_sent_total"]; got != 3 {
+		t.Errorf("outbox_events_sent_total = %d, want 3", got)
+	}
+
+	// Sent events are not sent again
+	relay.drain(context.Background())
+	if got := len(sender.sentIDs()); got != 3 {
+		t.Errorf("second drain sent %d more events", got-3)
+	}
+}
+
+func TestRelayKeepsKeyOrderAcrossFailures(t *testing.T) {
+	clock := newTestClock()
+	store := newMemoryStore(clock)
+	store.add(events.TopicURLCreated, "k1", "") // 1
+	store.add(events.TopicURLUpdated, "k1", "") // 2: other topic, own order
+	store.add(events.TopicURLCreated, "k1", "") // 3
+	store.add(events.TopicURLCreated, "k2", "") // 4
+	store.add(events.TopicURLCreated, "k1", "") // 5
+
+	sender := &recordingSender{}
+	sender.setFailing("k1")
+	relay, _ := newTestRelay(store, sender, Config{MinBackoff: time.Second, MaxBackoff: time.Minute})
+
+	// k1 fails; its later events must wait for the failed ones instead of
+	// overtaking them
+	relay.drain(context.Background())
+	if got, want := sender.sentIDs(), []int64{4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %v while k1 is failing, want %v", got, want)
+	}
+
+	// Nothing is retried before the backoff has passed
+	sender.setFailing()
+	relay.drain(context.Background())
+	if got := sender.sentIDs(); len(got) != 1 {
+		t.Fatalf("sent %v before the backoff passed, want only [4]", got)
+	}
+
+	clock.Advance(time.Second)
+	relay.drain(context.Background())
+	if got, want := sender.sentIDs(), []int64{4, 1, 2, 3, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+}
+
+func TestRelayBacksOffExponentially(t *testing.T) {
+	clock := newTestClock()
+	store := newMemoryStore(clock)
+	id := store.add(events.TopicURLCreated, "abc123", "")
+
+	sender := &recordingSender{}
+	sender.setFailing("abc123")
+	relay, collector := newTestRelay(store, sender, Config{
+		MinBackoff: time.Second,
+		MaxBackoff: 8 * time.Second,
+	})
+
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		before := clock.Now()
+		relay.drain(context.Background())
+
+		e := store.get(id)
+		if e.Attempts != i+1 || e.lastError != errKafkaDown.Error() {
+			t.Fatalf("after failure %d: attempts %d, last error %q", i+1, e.Attempts, e.lastError)
+		}
+		delay := e.nextAttemptAt.Sub(before)
+		delays = append(delays, delay)
+		clock.Advance(delay)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second,
+		8 * time.Second, 8 * time.Second, 8 * time.Second}
+	if !reflect.DeepEqual(delays, want) {
+		t.Errorf("retry delays = %v, want %v", delays, want)
+	}
+	if got := collector.GetCounters()["outbox_events_failed_total"]; got != 6 {
+		t.Errorf("outbox_events_failed_total = %d, want 6", got)
+	}
+
+	// Once Kafka is back the event goes out and is marked sent
+	sender.setFailing()
+	relay.drain(context.Background())
+	if store.get(id).sentAt.IsZero() {
+		t.Error("event not marked sent after recovery")
+	}
+}
+
+func TestRelayRetriesEventsWithInvalidHeaders(t *testing.T) {
+	store := newMemoryStore(newTestClock())
+	bad := store.add(events.TopicURLCreated, "abc123", "{not json")
+	store.add(events.TopicURLCreated, "def456", "")
+
+	sender := &recordingSender{}
+	relay, _ := newTestRelay(store, sender, Config{})
+	relay.drain(context.Background())
+
+	if got, want := sender.sentIDs(), []int64{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+	if e := store.get(bad); e.Attempts != 1 || !e.sentAt.IsZero() {
+		t.Errorf("event with invalid headers = %+v, want one failed attempt", e)
+	}
+}
+
+// Relays running side by side send every event exactly once, and each
+// key's events in the order they were written
+func TestConcurrentRelaysKeepKeyOrder(t *testing.T) {
+	store := newMemoryStore(newTestClock())
+	for i := 0; i < 200; i++ {
+		store.add(events.TopicURLClicked, fmt.Sprintf("key-%d", i%7), "")
+	}
+
+	sender := &recordingSender{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		relay, _ := newTestRelay(store, sender, Config{BatchSize: 3})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for store.unsent() > 0 {
+				relay.drain(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids := sender.sentIDs()
+	if len(ids) != 200 {
+		t.Fatalf("sent %d events, want 200", len(ids))
+	}
+
+	seen := make(map[int64]bool)
+	last := make(map[int64]int64) // key index to last sent ID
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("event %d sent twice", id)
+		}
+		seen[id] = true
+
+		key := (id - 1) % 7
+		if id < last[key] {
+			t.Errorf("event %d of key-%d sent after event %d", id, key, last[key])
+		}
+		last[key] = id
+	}
+}
+
+func TestRelayCleanup(t *testing.T) {
+	clock := newTestClock()
+	store := newMemoryStore(clock)
+	sender := &recordingSender{}
+	relay, _ := newTestRelay(store, sender, Config{Retention: 24 * time.Hour})
+
+	old := store.add(events.TopicURLCreated, "a", "")
+	relay.drain(context.Background())
+
+	clock.Advance(20 * time.Hour)
+	recent := store.add(events.TopicURLCreated, "b", "")
+	relay.drain(context.Background())
+
+	sender.setFailing("c")
+	unsent := store.add(events.TopicURLCreated, "c", "")
+	relay.drain(context.Background())
+
+	clock.Advance(5 * time.Hour)
+	relay.cleanup(context.Background())
+
+	var kept []int64
+	for _, e := range store.events {
+		kept = append(kept, e.ID)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i] < kept[j] })
+	if want := []int64{recent, unsent}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept events %v, want %v (event %d was sent 25h ago)", kept, want, old)
+	}
+}
@@ -11,25 +11,74 @@ import (
 	_ "github.com/lib/pq"
 
 	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/shortcode"
 )
 
 type PostgresRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	idEncoder shortcode.IDEncoder
+	checker   shortcode.CodeChecker
+	publisher domain.EventPublisher
 }
 
-func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+// maxReservedIDAttempts bounds how many IDs are skipped looking for one
+// whose derived short code passes the CodeChecker
+const maxReservedIDAttempts = 10
+
+type Config struct {
+	// IDEncoder derives short codes from row IDs. When set, URLs created
+	// without a short code get one assigned from their reserved ID.
+	IDEncoder shortcode.IDEncoder
+	// CodeChecker vets codes derived by IDEncoder; IDs whose code it
+	// rejects are skipped
+	CodeChecker shortcode.CodeChecker
+	// Publisher announces URLs the repository soft-deletes on its own
+	// when it finds them expired
+	Publisher domain.EventPublisher
 }
 
+func NewPostgresRepository(db *sqlx.DB, config Config) *PostgresRepository {
+	return &PostgresRepository{
+		db:        db,
+		idEncoder: config.IDEncoder,
+		checker:   config.CodeChecker,
+		publisher: config.Publisher,
+	}
+}
+
+// Create inserts the URL and records its first revision in one transaction
 func (r *PostgresRepository) Create(ctx context.Context, url *domain.URL) error {
+	return r.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		var err error
+
+		if url.ShortCode == "" && r.idEncoder != nil {
+			err = r.createWithReservedID(ctx, tx, url)
+		} else {
+			err = r.insert(ctx, tx, url)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = insertRevision(ctx, tx, url.ID, domain.RevisionActionCreate, nil, nil, url.Snapshot())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *PostgresRepository) insert(ctx context.Context, tx *sqlx.Tx, url *domain.URL) error {
 	query := `
-        INSERT INTO urls (short_code, original_url, user_id, expires_at, 
-                         is_active, metadata)
-        VALUES (:short_code, :original_url, :user_id, :expires_at, 
-                :is_active, :metadata)
+        INSERT INTO urls (short_code, original_url, canonical_url, user_id,
+                         expires_at, is_active, metadata)
+        VALUES (:short_code, :original_url, :canonical_url, :user_id,
+                :expires_at, :is_active, :metadata)
         RETURNING id, created_at`
 
-	rows, err := r.db.NamedQueryContext(ctx, query, url)
+	rows, err := sqlx.NamedQueryContext(ctx, tx, query, url)
 	if err != nil {
 		return fmt.Errorf("failed to insert URL: %w", err)
 	}
@@ -42,7 +91,66 @@ func (r *PostgresRepository) Create(ctx context.Context, url *domain.URL) error
 		}
 	}
 
-	return nil
+	return rows.Err()
+}
+
+// createWithReservedID is a two-phase create: it reserves the next row ID
+// from the sequence, derives the short code from it and then inserts the
+// row under that ID. IDs whose code the CodeChecker rejects are left
+// unused and the next one is reserved.
+func (r *PostgresRepository) createWithReservedID(ctx context.Context, tx *sqlx.Tx, url *domain.URL) error {
+	id, shortCode, err := r.reserveID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	url.ID = id
+	url.ShortCode = shortCode
+
+	query := `
+        INSERT INTO urls (id, short_code, original_url, canonical_url, user_id,
+                         expires_at, is_active, metadata)
+        VALUES (:id, :short_code, :original_url, :canonical_url, :user_id,
+                :expires_at, :is_active, :metadata)
+        RETURNING created_at`
+
+	rows, err := sqlx.NamedQueryContext(ctx, tx, query, url)
+	if err != nil {
+		return fmt.Errorf("failed to insert URL: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&url.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan returning values: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// reserveID takes IDs from the sequence until one encodes to a code that
+// passes the CodeChecker
+func (r *PostgresRepository) reserveID(ctx context.Context, tx *sqlx.Tx) (int64, string, error) {
+	for i := 0; i < maxReservedIDAttempts; i++ {
+		var id int64
+		err := tx.GetContext(ctx, &id, `SELECT nextval(pg_get_serial_sequence('urls', 'id'))`)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to reserve URL id: %w", err)
+		}
+
+		shortCode, err := r.idEncoder.Encode(id)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to encode short code: %w", err)
+		}
+
+		if r.checker == nil || r.checker.Check(shortCode) == nil {
+			return id, shortCode, nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("%w: no clean code after %d reserved ids",
+		shortcode.ErrBlockedCode, maxReservedIDAttempts)
 }
 
 func (r *PostgresRepository) GetByOriginalURLAndUser(ctx context.Context,
@@ -51,7 +159,7 @@ func (r *PostgresRepository) GetByOriginalURLAndUser(ctx context.Context,
 	var url domain.URL
 	query := `
         SELECT id, short_code, original_url, user_id, created_at, 
-               expires_at, click_count, is_active, metadata, updated_at
+               expires_at, click_count, bot_click_count, is_active, metadata, updated_at
         FROM urls
         WHERE original_url = $1 
           AND user_id = $2 
@@ -78,13 +186,7 @@ func (r *PostgresRepository) GetByOriginalURLAndUser(ctx context.Context,
 	// Check expiration in application and soft delete if expired
 	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
 		// log.Printf("DEBUG: URL %s is expired, soft deleting", url.ShortCode)
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			r.SoftDelete(ctx, url.ShortCode)
-			// Remove: log.Printf("DEBUG: Failed to soft delete: %v", err)
-			// Remove: log.Printf("DEBUG: Successfully soft deleted %s", url.ShortCode)
-		}()
+		go r.expire(url)
 		return nil, nil
 	}
 
@@ -92,14 +194,81 @@ func (r *PostgresRepository) GetByOriginalURLAndUser(ctx context.Context,
 	return &url, nil
 }
 
+// GetByCanonicalURLAndUser finds the user's live URL with the given
+// canonical form, so equivalent spellings dedupe to one short code
+func (r *PostgresRepository) GetByCanonicalURLAndUser(ctx context.Context,
+	canonicalURL string, userID int64) (*domain.URL, error) {
+
+	var url domain.URL
+	query := `
+        SELECT id, short_code, original_url, canonical_url, user_id,
+               created_at, expires_at, click_count, bot_click_count, is_active, metadata,
+               updated_at
+        FROM urls
+        WHERE canonical_url = $1
+          AND user_id = $2
+          AND deleted_at IS NULL
+        ORDER BY created_at DESC
+        LIMIT 1`
+
+	err := r.db.GetContext(ctx, &url, query, canonicalURL, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	// Check expiration in application and soft delete if expired
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		go r.expire(url)
+		return nil, nil
+	}
+
+	return &url, nil
+}
+
 func (r *PostgresRepository) SoftDelete(ctx context.Context, shortCode string) error {
 	query := `
         UPDATE urls 
         SET deleted_at = NOW(), updated_at = NOW()
         WHERE short_code = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.ExecContext(ctx, query, shortCode)
-	return err
+	result, err := r.conn(ctx).ExecContext(ctx, query, shortCode)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// expire soft-deletes an expired URL found during a lookup and announces
+// the deletion in the same transaction. Runs in the background, off the
+// request path.
+func (r *PostgresRepository) expire(url domain.URL) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r.WithinTx(ctx, func(ctx context.Context) error {
+		// Another lookup may have expired it first
+		if err := r.SoftDelete(ctx, url.ShortCode); err != nil {
+			return err
+		}
+
+		if r.publisher == nil {
+			return nil
+		}
+		url.DeletedAt = time.Now()
+		return r.publisher.PublishURLDeleted(ctx, &url, domain.DeleteReasonExpirySweep)
+	})
 }
 
 // FIXED: Remove the old GetByOriginalURL method or keep it if you need it for other purposes
@@ -108,7 +277,7 @@ func (r *PostgresRepository) GetByOriginalURL(ctx context.Context, originalURL s
 
 	query := `
 		SELECT id, short_code, original_url, user_id, created_at, 
-			   expires_at, click_count, is_active, metadata
+			   expires_at, click_count, bot_click_count, is_active, metadata
 		FROM urls
 		WHERE original_url = $1 AND is_active = true
 		ORDER BY created_at DESC
@@ -132,9 +301,33 @@ func (r *PostgresRepository) GetByOriginalURL(ctx context.Context, originalURL s
 
 func (r *PostgresRepository) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
 	var url domain.URL
+
+	// Codes derived from the row ID resolve through the primary key. Custom
+	// aliases don't decode (or decode to another row) and fall through to
+	// the short code lookup below.
+	if r.idEncoder != nil {
+		if id, err := r.idEncoder.Decode(shortCode); err == nil {
+			query := `
+        SELECT id, short_code, original_url,
+               COALESCE(canonical_url, original_url) AS canonical_url,
+               user_id, created_at, expires_at, click_count, bot_click_count, is_active, metadata
+        FROM urls
+        WHERE id = $1 AND short_code = $2 AND is_active = true AND deleted_at IS NULL`
+
+			err := r.db.GetContext(ctx, &url, query, id, shortCode)
+			if err == nil {
+				return checkNotExpired(&url), nil
+			}
+			if err != sql.ErrNoRows {
+				return nil, fmt.Errorf("failed to get URL: %w", err)
+			}
+		}
+	}
+
 	query := `
-        SELECT id, short_code, original_url, user_id, created_at, 
-               expires_at, click_count, is_active, metadata
+        SELECT id, short_code, original_url,
+               COALESCE(canonical_url, original_url) AS canonical_url,
+               user_id, created_at, expires_at, click_count, bot_click_count, is_active, metadata
         FROM urls
         WHERE short_code = $1 AND is_active = true AND deleted_at IS NULL`
 
@@ -146,14 +339,41 @@ func (r *PostgresRepository) GetByShortCode(ctx context.Context, shortCode strin
 		return nil, fmt.Errorf("failed to get URL: %w", err)
 	}
 
-	// Check expiration
-	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
-		return nil, nil
+	return checkNotExpired(&url), nil
+}
+
+// GetByShortCodeIncludingInactive looks up a URL that has not been deleted,
+// whether or not it is active, so it can be edited or reactivated
+func (r *PostgresRepository) GetByShortCodeIncludingInactive(ctx context.Context, shortCode string) (*domain.URL, error) {
+	var url domain.URL
+
+	query := `
+        SELECT id, short_code, original_url,
+               COALESCE(canonical_url, original_url) AS canonical_url,
+               user_id, created_at, expires_at, click_count, bot_click_count, is_active, metadata,
+               updated_at
+        FROM urls
+        WHERE short_code = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &url, query, shortCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get URL: %w", err)
 	}
 
 	return &url, nil
 }
 
+// checkNotExpired returns nil for URLs past their expiry
+func checkNotExpired(url *domain.URL) *domain.URL {
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		return nil
+	}
+	return url
+}
+
 func (r *PostgresRepository) IncrementClickCount(ctx context.Context, shortCode string) error {
 	query := `
 		UPDATE urls 
@@ -177,32 +397,17 @@ func (r *PostgresRepository) IncrementClickCount(ctx context.Context, shortCode
 	return nil
 }
 
-func (r *PostgresRepository) Update(ctx context.Context, url *domain.URL) error {
+// IncrementBotClickCount counts a click classified as automated, leaving
+// click_count to human clicks
+func (r *PostgresRepository) IncrementBotClickCount(ctx context.Context, shortCode string) error {
 	query := `
-		UPDATE urls 
-		SET user_id = $1, 
-			expires_at = $2, 
-			metadata = $3,
-			updated_at = NOW()
-		WHERE short_code = $4 AND is_active = true`
-
-	var metadataJSON []byte
-	if url.Metadata != nil && len(url.Metadata) > 0 {
-		var err error
-		metadataJSON, err = json.Marshal(url.Metadata)
-		if err != nil {
-			return fmt.Errorf("failed to marshal metadata: %w", err)
-		}
-	}
-
-	result, err := r.db.ExecContext(ctx, query,
-		url.UserID,
-		url.ExpiresAt,
-		metadataJSON,
-		url.ShortCode)
+		UPDATE urls
+		SET bot_click_count = bot_click_count + 1
+		WHERE short_code = $1 AND is_active = true`
 
+	result, err := r.db.ExecContext(ctx, query, shortCode)
 	if err != nil {
-		return fmt.Errorf("failed to update URL: %w", err)
+		return fmt.Errorf("failed to increment bot click count: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -211,12 +416,82 @@ func (r *PostgresRepository) Update(ctx context.Context, url *domain.URL) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("URL with short code %s not found or not active", url.ShortCode)
+		return fmt.Errorf("URL with short code %s not found or not active", shortCode)
 	}
 
 	return nil
 }
 
+// Update saves the URL and records a revision with the before and after
+// snapshots in one transaction
+func (r *PostgresRepository) Update(ctx context.Context, url *domain.URL) error {
+	return r.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		var err error
+
+		// Lock the row so revisions are numbered in update order
+		var before domain.URL
+		err = tx.GetContext(ctx, &before, `
+			SELECT id, original_url, expires_at, is_active, metadata
+			FROM urls
+			WHERE short_code = $1 AND deleted_at IS NULL
+			FOR UPDATE`, url.ShortCode)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("URL with short code %s not found or deleted", url.ShortCode)
+			}
+			return fmt.Errorf("failed to get URL: %w", err)
+		}
+
+		query := `
+			UPDATE urls 
+			SET user_id = $1, 
+				expires_at = $2, 
+				metadata = $3,
+				original_url = $4,
+				canonical_url = $5,
+				is_active = $6,
+				updated_at = NOW()
+			WHERE id = $7
+			RETURNING updated_at`
+
+		var metadataJSON []byte
+		if url.Metadata != nil && len(url.Metadata) > 0 {
+			metadataJSON, err = json.Marshal(url.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+		}
+
+		var canonicalURL *string
+		if url.CanonicalURL != "" {
+			canonicalURL = &url.CanonicalURL
+		}
+
+		err = tx.QueryRowContext(ctx, query,
+			url.UserID,
+			url.ExpiresAt,
+			metadataJSON,
+			url.OriginalURL,
+			canonicalURL,
+			url.IsActive,
+			before.ID).Scan(&url.UpdatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to update URL: %w", err)
+		}
+
+		beforeSnapshot, afterSnapshot := before.Snapshot(), url.Snapshot()
+		err = insertRevision(ctx, tx, before.ID, domain.RevisionActionUpdate,
+			changedFields(beforeSnapshot, afterSnapshot), beforeSnapshot, afterSnapshot)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
 // FIXED: Update Delete method signature to match interface
 func (r *PostgresRepository) Delete(ctx context.Context, shortCode string) error {
 	query := `
@@ -226,7 +501,7 @@ func (r *PostgresRepository) Delete(ctx context.Context, shortCode string) error
             updated_at = NOW()
         WHERE short_code = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, shortCode)
+	result, err := r.conn(ctx).ExecContext(ctx, query, shortCode)
 	if err != nil {
 		return fmt.Errorf("failed to delete URL: %w", err)
 	}
@@ -243,12 +518,98 @@ func (r *PostgresRepository) Delete(ctx context.Context, shortCode string) error
 	return nil
 }
 
+// GetDeletedByShortCode returns the soft-deleted URL with the given short
+// code, or nil if there is none
+func (r *PostgresRepository) GetDeletedByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	var url domain.URL
+
+	query := `
+        SELECT id, short_code, original_url,
+               COALESCE(canonical_url, original_url) AS canonical_url,
+               user_id, created_at, expires_at, click_count, bot_click_count, is_active, metadata,
+               updated_at, deleted_at
+        FROM urls
+        WHERE short_code = $1 AND deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+        LIMIT 1`
+
+	err := r.db.GetContext(ctx, &url, query, shortCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deleted URL: %w", err)
+	}
+
+	return &url, nil
+}
+
+// Restore clears deleted_at and reactivates a soft-deleted URL, recording
+// the restore as a revision
+func (r *PostgresRepository) Restore(ctx context.Context, url *domain.URL) error {
+	return r.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		var err error
+
+		query := `
+	        UPDATE urls
+	        SET deleted_at = NULL,
+	            is_active = true,
+	            updated_at = NOW()
+	        WHERE id = $1 AND deleted_at IS NOT NULL
+	        RETURNING updated_at`
+
+		before := url.Snapshot()
+		if err := tx.QueryRowContext(ctx, query, url.ID).Scan(&url.UpdatedAt); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("URL with short code %s is not deleted", url.ShortCode)
+			}
+			return fmt.Errorf("failed to restore URL: %w", err)
+		}
+		url.IsActive = true
+		url.DeletedAt = time.Time{}
+
+		after := url.Snapshot()
+		err = insertRevision(ctx, tx, url.ID, domain.RevisionActionRestore,
+			changedFields(before, after), before, after)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// PurgeDeleted hard-deletes up to limit URLs soft-deleted before the given
+// time, along with their revisions, and returns them
+func (r *PostgresRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]*domain.URL, error) {
+	var urls []*domain.URL
+	query := `
+        DELETE FROM urls
+        WHERE id IN (
+            SELECT id FROM urls
+            WHERE deleted_at < $1
+            ORDER BY deleted_at
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id, short_code, original_url, user_id, created_at,
+                  deleted_at`
+
+	err := r.conn(ctx).SelectContext(ctx, &urls, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted URLs: %w", err)
+	}
+
+	return urls, nil
+}
+
 // FIXED: Rename method to match interface
 func (r *PostgresRepository) GetUserURLs(ctx context.Context, userID int64, limit, offset int) ([]*domain.URL, error) {
 	var urls []*domain.URL
 	query := `
         SELECT id, short_code, original_url, user_id, created_at, 
-               expires_at, click_count, is_active, metadata
+               expires_at, click_count, bot_click_count, is_active, metadata
         FROM urls
         WHERE user_id = $1 AND is_active = true
         ORDER BY created_at DESC
@@ -2,15 +2,27 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
 )
 
 type Repository interface {
 	Create(ctx context.Context, url *domain.URL) error
 	GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error)
-	GetByOriginalURLAndUser(ctx context.Context, originalURL string, userID int64) (*domain.URL, error)
+	GetByShortCodeIncludingInactive(ctx context.Context, shortCode string) (*domain.URL, error)
+	GetByCanonicalURLAndUser(ctx context.Context, canonicalURL string, userID int64) (*domain.URL, error)
 	Update(ctx context.Context, url *domain.URL) error
 	GetUserURLs(ctx context.Context, userID int64, limit, offset int) ([]*domain.URL, error)
 	Delete(ctx context.Context, shortCode string) error
 	IncrementClickCount(ctx context.Context, shortCode string) error
+	IncrementBotClickCount(ctx context.Context, shortCode string) error
+	GetRevisions(ctx context.Context, shortCode string) ([]*domain.URLRevision, error)
+	GetRevision(ctx context.Context, shortCode string, revision int) (*domain.URLRevision, error)
+	GetDeletedByShortCode(ctx context.Context, shortCode string) (*domain.URL, error)
+	Restore(ctx context.Context, url *domain.URL) error
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]*domain.URL, error)
+	// WithinTx runs fn in one transaction; writes made with the context
+	// passed to fn, including outbox events, commit together
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
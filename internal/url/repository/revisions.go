@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+)
+
+type revisionRow struct {
+	Revision      int            `db:"revision"`
+	ShortCode     string         `db:"short_code"`
+	Action        string         `db:"action"`
+	ChangedBy     string         `db:"changed_by"`
+	ChangedFields pq.StringArray `db:"changed_fields"`
+	Before        []byte         `db:"before"`
+	After         []byte         `db:"after"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+func (row *revisionRow) toDomain() (*domain.URLRevision, error) {
+	revision := &domain.URLRevision{
+		Revision:      row.Revision,
+		ShortCode:     row.ShortCode,
+		Action:        row.Action,
+		ChangedBy:     row.ChangedBy,
+		ChangedFields: row.ChangedFields,
+		After:         &domain.URLSnapshot{},
+		CreatedAt:     row.CreatedAt,
+	}
+
+	if row.Before != nil {
+		revision.Before = &domain.URLSnapshot{}
+		if err := json.Unmarshal(row.Before, revision.Before); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal revision snapshot: %w", err)
+		}
+	}
+	if err := json.Unmarshal(row.After, revision.After); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal revision snapshot: %w", err)
+	}
+
+	return revision, nil
+}
+
+const revisionColumns = `
+        SELECT rv.revision, u.short_code, rv.action,
+               COALESCE(rv.changed_by, '') AS changed_by, rv.changed_fields,
+               rv.before, rv.after, rv.created_at
+        FROM url_revisions rv
+        JOIN urls u ON u.id = rv.url_id`
+
+// GetRevisions returns the revision history of a live URL, newest first
+func (r *PostgresRepository) GetRevisions(ctx context.Context, shortCode string) ([]*domain.URLRevision, error) {
+	var rows []revisionRow
+	query := revisionColumns + `
+        WHERE u.short_code = $1 AND u.deleted_at IS NULL
+        ORDER BY rv.revision DESC`
+
+	if err := r.db.SelectContext(ctx, &rows, query, shortCode); err != nil {
+		return nil, fmt.Errorf("failed to get URL revisions: %w", err)
+	}
+
+	revisions := make([]*domain.URLRevision, len(rows))
+	for i := range rows {
+		revision, err := rows[i].toDomain()
+		if err != nil {
+			return nil, err
+		}
+		revisions[i] = revision
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns one revision of a live URL, or nil if it doesn't exist
+func (r *PostgresRepository) GetRevision(ctx context.Context, shortCode string, revision int) (*domain.URLRevision, error) {
+	var row revisionRow
+	query := revisionColumns + `
+        WHERE u.short_code = $1 AND u.deleted_at IS NULL AND rv.revision = $2`
+
+	err := r.db.GetContext(ctx, &row, query, shortCode, revision)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get URL revision: %w", err)
+	}
+
+	return row.toDomain()
+}
+
+// insertRevision appends the next revision of a URL. The caller must hold
+// the URL's row (by inserting or locking it) so numbers don't collide.
+func insertRevision(ctx context.Context, tx *sqlx.Tx, urlID int64, action string,
+	changedFields []string, before, after *domain.URLSnapshot) error {
+
+	var beforeJSON []byte
+	if before != nil {
+		var err error
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return fmt.Errorf("failed to marshal revision snapshot: %w", err)
+		}
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision snapshot: %w", err)
+	}
+
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	query := `
+        INSERT INTO url_revisions (url_id, revision, action, changed_by,
+                                   changed_fields, before, after)
+        SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, NULLIF($3, ''), $4, $5, $6
+        FROM url_revisions
+        WHERE url_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, urlID, action, domain.ActorFromContext(ctx),
+		pq.Array(changedFields), beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record URL revision: %w", err)
+	}
+
+	return nil
+}
+
+// changedFields lists the snapshot fields that differ between revisions
+func changedFields(before, after *domain.URLSnapshot) []string {
+	var fields []string
+	if before.OriginalURL != after.OriginalURL {
+		fields = append(fields, "original_url")
+	}
+	if !sameTime(before.ExpiresAt, after.ExpiresAt) {
+		fields = append(fields, "expires_at")
+	}
+	if before.IsActive != after.IsActive {
+		fields = append(fields, "is_active")
+	}
+	if !sameMetadata(before.Metadata, after.Metadata) {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// sameMetadata compares metadata through JSON, the form it is stored in
+func sameMetadata(a, b domain.JSONB) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var decodedA, decodedB interface{}
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	json.Unmarshal(dataA, &decodedA)
+	json.Unmarshal(dataB, &decodedB)
+
+	return reflect.DeepEqual(decodedA, decodedB)
+}
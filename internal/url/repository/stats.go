@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/hll"
+)
+
+// StatsRepository reads the click aggregates maintained by the
+// click-consumer
+type StatsRepository interface {
+	// GetURLStats returns the clicks of req.ShortCode in [req.From, req.To),
+	// which the caller aligns to bucket boundaries. Series only holds
+	// buckets with clicks.
+	GetURLStats(ctx context.Context, req *domain.URLStatsRequest) (*domain.URLStats, error)
+}
+
+// statsDimensions maps the top lists to their aggregate columns
+var statsDimensions = []struct {
+	column string
+	top    func(*domain.URLStats) *[]domain.StatsCount
+}{
+	{"referrer_domain", func(s *domain.URLStats) *[]domain.StatsCount { return &s.TopReferrers }},
+	{"country", func(s *domain.URLStats) *[]domain.StatsCount { return &s.TopCountries }},
+	{"device", func(s *domain.URLStats) *[]domain.StatsCount { return &s.TopDevices }},
+	{"browser", func(s *domain.URLStats) *[]domain.StatsCount { return &s.TopBrowsers }},
+}
+
+type PostgresStatsRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStatsRepository(db *sqlx.DB) *PostgresStatsRepository {
+	return &PostgresStatsRepository{db: db}
+}
+
+func (r *PostgresStatsRepository) GetURLStats(ctx context.Context, req *domain.URLStatsRequest) (*domain.URLStats, error) {
+	// Day and week series are both built from the daily aggregates
+	clicksTable, uniquesTable := "click_stats_daily", "click_uniques_daily"
+	if req.Granularity == domain.StatsGranularityHour {
+		clicksTable, uniquesTable = "click_stats_hourly", "click_uniques_hourly"
+	}
+
+	bucket := "bucket"
+	if req.Granularity == domain.StatsGranularityWeek {
+		// ISO weeks, starting on Monday like domain.StatsBucketStart
+		bucket = "date_trunc('week', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+	}
+
+	stats := &domain.URLStats{
+		ShortCode:   req.ShortCode,
+		Granularity: req.Granularity,
+		From:        req.From,
+		To:          req.To,
+	}
+
+	var series []struct {
+		Start  time.Time `db:"start"`
+		Clicks int64     `db:"clicks"`
+	}
+	err := r.db.SelectContext(ctx, &series, fmt.Sprintf(`
+        SELECT %s AS start, SUM(clicks) AS clicks
+        FROM %s
+        WHERE short_code = $1 AND bucket >= $2 AND bucket < $3
+        GROUP BY 1
+        ORDER BY 1`, bucket, clicksTable), req.ShortCode, req.From, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click series: %w", err)
+	}
+
+	uniques, total, err := r.uniqueVisitors(ctx, uniquesTable, req)
+	if err != nil {
+		return nil, err
+	}
+	stats.UniqueVisitors = total
+
+	for _, point := range series {
+		start := point.Start.UTC()
+		stats.TotalClicks += point.Clicks
+		stats.Series = append(stats.Series, domain.StatsBucket{
+			Start:          start,
+			Clicks:         point.Clicks,
+			UniqueVisitors: uniques[start],
+		})
+	}
+
+	for _, dim := range statsDimensions {
+		var top []domain.StatsCount
+		err := r.db.SelectContext(ctx, &top, fmt.Sprintf(`
+            SELECT %[1]s AS value, SUM(clicks) AS clicks
+            FROM %[2]s
+            WHERE short_code = $1 AND bucket >= $2 AND bucket < $3
+            GROUP BY %[1]s
+            ORDER BY clicks DESC, value
+            LIMIT $4`, dim.column, clicksTable),
+			req.ShortCode, req.From, req.To, req.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top %s: %w", dim.column, err)
+		}
+		*dim.top(stats) = top
+	}
+
+	return stats, nil
+}
+
+// uniqueVisitors merges the visitor sketches in range into one estimate
+// per series bucket and one for the whole range
+func (r *PostgresStatsRepository) uniqueVisitors(ctx context.Context, table string,
+	req *domain.URLStatsRequest) (map[time.Time]int64, int64, error) {
+
+	var rows []struct {
+		Bucket time.Time `db:"bucket"`
+		Sketch []byte    `db:"sketch"`
+	}
+	err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(`
+        SELECT bucket, sketch
+        FROM %s
+        WHERE short_code = $1 AND bucket >= $2 AND bucket < $3`, table),
+		req.ShortCode, req.From, req.To)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get visitor sketches: %w", err)
+	}
+
+	total := hll.New()
+	buckets := make(map[time.Time]*hll.Sketch)
+	for _, row := range rows {
+		sketch, err := hll.Unmarshal(row.Sketch)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read visitor sketch: %w", err)
+		}
+		total.Merge(sketch)
+
+		start := domain.StatsBucketStart(row.Bucket, req.Granularity)
+		if merged, ok := buckets[start]; ok {
+			merged.Merge(sketch)
+		} else {
+			buckets[start] = sketch
+		}
+	}
+
+	estimates := make(map[time.Time]int64, len(buckets))
+	for start, sketch := range buckets {
+		estimates[start] = int64(sketch.Estimate())
+	}
+
+	return estimates, int64(total.Estimate()), nil
+}
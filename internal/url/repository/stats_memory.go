@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/pkg/hll"
+)
+
+// StatsClick is a click recorded in a MemoryStatsRepository
+type StatsClick struct {
+	ShortCode      string
+	At             time.Time
+	ReferrerDomain string
+	Country        string
+	Device         string
+	Browser        string
+	Visitor        string // "" when unknown
+}
+
+// MemoryStatsRepository computes stats from individual clicks kept in
+// memory, for tests and local runs
+type MemoryStatsRepository struct {
+	mu     sync.RWMutex
+	clicks []StatsClick
+}
+
+func NewMemoryStatsRepository() *MemoryStatsRepository {
+	return &MemoryStatsRepository{}
+}
+
+// AddClick records a click
+func (r *MemoryStatsRepository) AddClick(click StatsClick) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clicks = append(r.clicks, click)
+}
+
+func (r *MemoryStatsRepository) GetURLStats(ctx context.Context, req *domain.URLStatsRequest) (*domain.URLStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &domain.URLStats{
+		ShortCode:   req.ShortCode,
+		Granularity: req.Granularity,
+		From:        req.From,
+		To:          req.To,
+	}
+
+	clicks := make(map[time.Time]int64)
+	visitors := make(map[time.Time]*hll.Sketch)
+	total := hll.New()
+	counts := make([]map[string]int64, len(statsDimensions))
+	for i := range counts {
+		counts[i] = make(map[string]int64)
+	}
+
+	for _, click := range r.clicks {
+		if click.ShortCode != req.ShortCode || click.At.Before(req.From) || !click.At.Before(req.To) {
+			continue
+		}
+
+		start := domain.StatsBucketStart(click.At, req.Granularity)
+		clicks[start]++
+		stats.TotalClicks++
+
+		if click.Visitor != "" {
+			sketch, ok := visitors[start]
+			if !ok {
+				sketch = hll.New()
+				visitors[start] = sketch
+			}
+			sketch.AddString(click.Visitor)
+			total.AddString(click.Visitor)
+		}
+
+		for i, value := range []string{click.ReferrerDomain, click.Country, click.Device, click.Browser} {
+			counts[i][value]++
+		}
+	}
+
+	for start, n := range clicks {
+		bucket := domain.StatsBucket{Start: start, Clicks: n}
+		if sketch, ok := visitors[start]; ok {
+			bucket.UniqueVisitors = int64(sketch.Estimate())
+		}
+		stats.Series = append(stats.Series, bucket)
+	}
+	sort.Slice(stats.Series, func(i, j int) bool {
+		return stats.Series[i].Start.Before(stats.Series[j].Start)
+	})
+	stats.UniqueVisitors = int64(total.Estimate())
+
+	for i, dim := range statsDimensions {
+		*dim.top(stats) = topCounts(counts[i], req.Limit)
+	}
+
+	return stats, nil
+}
+
+// topCounts orders counts by clicks, then value, and keeps the first limit
+func topCounts(counts map[string]int64, limit int) []domain.StatsCount {
+	top := make([]domain.StatsCount, 0, len(counts))
+	for value, n := range counts {
+		top = append(top, domain.StatsCount{Value: value, Clicks: n})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Clicks != top[j].Clicks {
+			return top[i].Clicks > top[j].Clicks
+		}
+		return top[i].Value < top[j].Value
+	})
+
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
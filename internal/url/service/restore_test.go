@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/repository"
+)
+
+// restoreRepository holds deleted URLs and one live URL per canonical URL
+type restoreRepository struct {
+	repository.Repository
+	deleted  map[string]*domain.URL
+	live     map[string]*domain.URL
+	restored []string
+}
+
+func (r *restoreRepository) GetDeletedByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	return r.deleted[shortCode], nil
+}
+
+func (r *restoreRepository) GetByCanonicalURLAndUser(ctx context.Context, canonicalURL string, userID int64) (*domain.URL, error) {
+	return r.live[canonicalURL], nil
+}
+
+func (r *restoreRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *restoreRepository) Restore(ctx context.Context, url *domain.URL) error {
+	r.restored = append(r.restored, url.ShortCode)
+	return nil
+}
+
+func TestRestoreURLRejectsExpiredURLs(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	repo := &restoreRepository{
+		deleted: map[string]*domain.URL{
+			"expired": {ID: 1, ShortCode: "expired", CanonicalURL: "https://a.example/", ExpiresAt: &past},
+			"later":   {ID: 2, ShortCode: "later", CanonicalURL: "https://b.example/", ExpiresAt: &future},
+		},
+		// Stops the restore of "later" after the expiry check, before the
+		// cache is touched
+		live: map[string]*domain.URL{"https://b.example/": {ID: 3, ShortCode: "again"}},
+	}
+	service := NewURLService(repo, nil, nil, nil, nil,
+		zap.NewNop(), metrics.NewInMemoryMetrics(), Config{})
+
+	for _, tc := range []struct {
+		shortCode string
+		want      error
+	}{
+		{"expired", domain.ErrURLExpired},
+		{"later", domain.ErrURLAlreadyShortened},
+		{"missing", domain.ErrURLNotFound},
+	} {
+		t.Run(tc.shortCode, func(t *testing.T) {
+			resp, err := service.RestoreURL(context.Background(), tc.shortCode)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("RestoreURL(%q) = %+v, %v; want %v", tc.shortCode, resp, err, tc.want)
+			}
+		})
+	}
+
+	if len(repo.restored) != 0 {
+		t.Errorf("restored %v, want nothing", repo.restored)
+	}
+}
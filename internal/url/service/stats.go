@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+)
+
+const (
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+	// maxStatsBuckets bounds the length of a click series
+	maxStatsBuckets = 2000
+)
+
+// defaultStatsRanges is how far back a stats request without From looks
+var defaultStatsRanges = map[string]time.Duration{
+	domain.StatsGranularityHour: 48 * time.Hour,
+	domain.StatsGranularityDay:  30 * 24 * time.Hour,
+	domain.StatsGranularityWeek: 12 * 7 * 24 * time.Hour,
+}
+
+var errStatsUnavailable = errors.New("link statistics are not configured")
+
+// GetURLStats returns the click statistics of a link. The range is widened
+// to whole buckets and the series has an entry, possibly zero, for every
+// bucket in it.
+func (s *URLService) GetURLStats(ctx context.Context, req domain.URLStatsRequest) (*domain.URLStats, error) {
+	if s.stats == nil {
+		return nil, errStatsUnavailable
+	}
+
+	if req.Granularity == "" {
+		req.Granularity = domain.StatsGranularityDay
+	}
+	lookback, ok := defaultStatsRanges[req.Granularity]
+	if !ok {
+		return nil, fmt.Errorf("%w: granularity must be hour, day or week",
+			domain.ErrInvalidStatsRequest)
+	}
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-lookback)
+	}
+	if !req.From.Before(req.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidStatsRequest)
+	}
+
+	// Align to buckets; To is exclusive, so round it up
+	req.From = domain.StatsBucketStart(req.From, req.Granularity)
+	if end := domain.StatsBucketStart(req.To, req.Granularity); end.Before(req.To) {
+		req.To = domain.NextStatsBucket(end, req.Granularity)
+	} else {
+		req.To = end
+	}
+
+	buckets := 0
+	for t := req.From; t.Before(req.To); t = domain.NextStatsBucket(t, req.Granularity) {
+		if buckets++; buckets > maxStatsBuckets {
+			return nil, fmt.Errorf("%w: range spans more than %d %s buckets",
+				domain.ErrInvalidStatsRequest, maxStatsBuckets, req.Granularity)
+		}
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultStatsLimit
+	}
+	req.Limit = min(req.Limit, maxStatsLimit)
+
+	url, err := s.repo.GetByShortCodeIncludingInactive(ctx, req.ShortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL: %w", err)
+	}
+	if url == nil {
+		return nil, domain.ErrURLNotFound
+	}
+
+	stats, err := s.stats.GetURLStats(ctx, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL stats: %w", err)
+	}
+	stats.Series = fillStatsSeries(stats.Series, &req)
+
+	return stats, nil
+}
+
+// fillStatsSeries returns a series with one entry per bucket in the range
+func fillStatsSeries(series []domain.StatsBucket, req *domain.URLStatsRequest) []domain.StatsBucket {
+	byStart := make(map[time.Time]domain.StatsBucket, len(series))
+	for _, bucket := range series {
+		byStart[bucket.Start] = bucket
+	}
+
+	filled := make([]domain.StatsBucket, 0)
+	for t := req.From; t.Before(req.To); t = domain.NextStatsBucket(t, req.Granularity) {
+		bucket, ok := byStart[t]
+		if !ok {
+			bucket = domain.StatsBucket{Start: t}
+		}
+		filled = append(filled, bucket)
+	}
+
+	return filled
+}
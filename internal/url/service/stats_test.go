@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"github.com/umanagarjuna/go-url-shortener/internal/url/repository"
+)
+
+// statsURLRepository knows one link; GetURLStats needs nothing else of the
+// repository
+type statsURLRepository struct {
+	repository.Repository
+	shortCode string
+}
+
+func (r *statsURLRepository) GetByShortCodeIncludingInactive(ctx context.Context, shortCode string) (*domain.URL, error) {
+	if shortCode != r.shortCode {
+		return nil, nil
+	}
+	return &domain.URL{ID: 1, ShortCode: shortCode, OriginalURL: "https://example.com"}, nil
+}
+
+func newStatsService(stats repository.StatsRepository) *URLService {
+	return NewURLService(&statsURLRepository{shortCode: "abc123"}, nil, nil, nil, nil,
+		zap.NewNop(), metrics.NewInMemoryMetrics(), Config{Stats: stats})
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func seriesStarts(stats *domain.URLStats) []time.Time {
+	starts := make([]time.Time, 0, len(stats.Series))
+	for _, bucket := range stats.Series {
+		starts = append(starts, bucket.Start)
+	}
+	return starts
+}
+
+func TestGetURLStatsAlignsBucketsInUTC(t *testing.T) {
+	service := newStatsService(repository.NewMemoryStatsRepository())
+
+	for _, tc := range []struct {
+		name        string
+		granularity string
+		from, to    string
+		wantFrom    string
+		wantTo      string
+		buckets     int
+	}{
+		{"hour", domain.StatsGranularityHour,
+			"2026-03-10T10:30:00+05:00", "2026-03-10T08:15:00Z",
+			"2026-03-10T05:00:00Z", "2026-03-10T09:00:00Z", 4},
+		{"hour already aligned", domain.StatsGranularityHour,
+			"2026-03-10T05:00:00Z", "2026-03-10T07:00:00Z",
+			"2026-03-10T05:00:00Z", "2026-03-10T07:00:00Z", 2},
+		// 01:00 in Tokyo is the previous day in UTC
+		{"day", domain.StatsGranularityDay,
+			"2026-03-10T01:00:00+09:00", "2026-03-11T23:00:00-02:00",
+			"2026-03-09T00:00:00Z", "2026-03-13T00:00:00Z", 4},
+		// 2026-03-11 is a Wednesday; weeks start on Monday
+		{"week", domain.StatsGranularityWeek,
+			"2026-03-11T12:00:00Z", "2026-03-23T00:00:00Z",
+			"2026-03-09T00:00:00Z", "2026-03-23T00:00:00Z", 2},
+		{"week across a month", domain.StatsGranularityWeek,
+			"2026-03-01T00:00:00Z", "2026-03-03T00:00:00Z",
+			"2026-02-23T00:00:00Z", "2026-03-09T00:00:00Z", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stats, err := service.GetURLStats(context.Background(), domain.URLStatsRequest{
+				ShortCode:   "abc123",
+				Granularity: tc.granularity,
+				From:        mustTime(t, tc.from),
+				To:          mustTime(t, tc.to),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := mustTime(t, tc.wantFrom); !stats.From.Equal(want) {
+				t.Errorf("From = %s, want %s", stats.From, want)
+			}
+			if want := mustTime(t, tc.wantTo); !stats.To.Equal(want) {
+				t.Errorf("To = %s, want %s", stats.To, want)
+			}
+			if len(stats.Series) != tc.buckets {
+				t.Fatalf("series has %d buckets, want %d: %v", len(stats.Series), tc.buckets, seriesStarts(stats))
+			}
+			for i, bucket := range stats.Series {
+				if bucket.Start.Location() != time.UTC {
+					t.Errorf("bucket %d starts in %s, want UTC", i, bucket.Start.Location())
+				}
+				if want := domain.StatsBucketStart(bucket.Start, tc.granularity); !bucket.Start.Equal(want) {
+					t.Errorf("bucket %d starts at %s, not on a bucket boundary", i, bucket.Start)
+				}
+			}
+		})
+	}
+}
+
+func TestGetURLStatsZeroFillsSeries(t *testing.T) {
+	stats := repository.NewMemoryStatsRepository()
+	for _, click := range []struct {
+		at      string
+		visitor string
+	}{
+		{"2026-03-10T05:10:00Z", "a"},
+		{"2026-03-10T05:50:00Z", "b"},
+		{"2026-03-10T07:05:00Z", "a"},
+		{"2026-03-10T09:00:00Z", "a"}, // outside the range
+	} {
+		stats.AddClick(repository.StatsClick{
+			ShortCode: "abc123",
+			At:        mustTime(t, click.at),
+			Visitor:   click.visitor,
+		})
+	}
+	stats.AddClick(repository.StatsClick{ShortCode: "other", At: mustTime(t, "2026-03-10T06:00:00Z")})
+
+	service := newStatsService(stats)
+	result, err := service.GetURLStats(context.Background(), domain.URLStatsRequest{
+		ShortCode:   "abc123",
+		Granularity: domain.StatsGranularityHour,
+		From:        mustTime(t, "2026-03-10T05:00:00Z"),
+		To:          mustTime(t, "2026-03-10T09:00:00Z"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []domain.StatsBucket{
+		{Start: mustTime(t, "2026-03-10T05:00:00Z"), Clicks: 2, UniqueVisitors: 2},
+		{Start: mustTime(t, "2026-03-10T06:00:00Z")},
+		{Start: mustTime(t, "2026-03-10T07:00:00Z"), Clicks: 1, UniqueVisitors: 1},
+		{Start: mustTime(t, "2026-03-10T08:00:00Z")},
+	}
+	if len(result.Series) != len(want) {
+		t.Fatalf("series = %v, want %v", result.Series, want)
+	}
+	for i := range want {
+		got := result.Series[i]
+		if !got.Start.Equal(want[i].Start) || got.Clicks != want[i].Clicks ||
+			got.UniqueVisitors != want[i].UniqueVisitors {
+			t.Errorf("bucket %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if result.TotalClicks != 3 {
+		t.Errorf("TotalClicks = %d, want 3", result.TotalClicks)
+	}
+
+	// A range without clicks is all zeros, not empty
+	result, err = service.GetURLStats(context.Background(), domain.URLStatsRequest{
+		ShortCode:   "abc123",
+		Granularity: domain.StatsGranularityDay,
+		From:        mustTime(t, "2025-01-01T00:00:00Z"),
+		To:          mustTime(t, "2025-01-08T00:00:00Z"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Series) != 7 || result.TotalClicks != 0 {
+		t.Errorf("empty range: %d buckets and %d clicks, want 7 and 0", len(result.Series), result.TotalClicks)
+	}
+	for i, bucket := range result.Series {
+		if bucket.Clicks != 0 {
+			t.Errorf("empty range bucket %d has %d clicks", i, bucket.Clicks)
+		}
+	}
+}
+
+func TestGetURLStatsClampsLimit(t *testing.T) {
+	stats := repository.NewMemoryStatsRepository()
+	at := mustTime(t, "2026-03-10T05:10:00Z")
+	for i := 0; i < 150; i++ {
+		stats.AddClick(repository.StatsClick{
+			ShortCode:      "abc123",
+			At:             at,
+			ReferrerDomain: fmt.Sprintf("site%03d.example", i),
+		})
+	}
+	service := newStatsService(stats)
+
+	for _, tc := range []struct {
+		limit int
+		want  int
+	}{
+		{0, defaultStatsLimit},
+		{-5, defaultStatsLimit},
+		{5, 5},
+		{100, 100},
+		{500, maxStatsLimit},
+	} {
+		t.Run(fmt.Sprint(tc.limit), func(t *testing.T) {
+			result, err := service.GetURLStats(context.Background(), domain.URLStatsRequest{
+				ShortCode: "abc123",
+				From:      at.Add(-time.Hour),
+				To:        at.Add(time.Hour),
+				Limit:     tc.limit,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.TopReferrers) != tc.want {
+				t.Errorf("Limit %d gave %d referrers, want %d", tc.limit, len(result.TopReferrers), tc.want)
+			}
+		})
+	}
+}
+
+func TestGetURLStatsDefaultRange(t *testing.T) {
+	service := newStatsService(repository.NewMemoryStatsRepository())
+
+	for _, tc := range []struct {
+		granularity string
+		lookback    time.Duration
+	}{
+		{"", 30 * 24 * time.Hour}, // day
+		{domain.StatsGranularityHour, 48 * time.Hour},
+		{domain.StatsGranularityDay, 30 * 24 * time.Hour},
+		{domain.StatsGranularityWeek, 12 * 7 * 24 * time.Hour},
+	} {
+		t.Run(tc.granularity, func(t *testing.T) {
+			before := time.Now()
+			result, err := service.GetURLStats(context.Background(), domain.URLStatsRequest{
+				ShortCode:   "abc123",
+				Granularity: tc.granularity,
+			})
+			after := time.Now()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			granularity := tc.granularity
+			if granularity == "" {
+				granularity = domain.StatsGranularityDay
+			}
+			if result.Granularity != granularity {
+				t.Errorf("Granularity = %q, want %q", result.Granularity, granularity)
+			}
+
+			// To is now rounded up to the next bucket, From is To minus the
+			// lookback rounded down; now may cross a boundary mid-call
+			if !statsRangeAround(result, before, tc.lookback, granularity) &&
+				!statsRangeAround(result, after, tc.lookback, granularity) {
+				t.Errorf("range = [%s, %s), want the %s before now (%s) in whole buckets",
+					result.From, result.To, tc.lookback, before)
+			}
+		})
+	}
+}
+
+func statsRangeAround(stats *domain.URLStats, now time.Time, lookback time.Duration, granularity string) bool {
+	from := domain.StatsBucketStart(now.Add(-lookback), granularity)
+	to := domain.NextStatsBucket(domain.StatsBucketStart(now, granularity), granularity)
+	return stats.From.Equal(from) && stats.To.Equal(to)
+}
+
+func TestGetURLStatsInvalidRequests(t *testing.T) {
+	service := newStatsService(repository.NewMemoryStatsRepository())
+	from := mustTime(t, "2026-03-10T00:00:00Z")
+
+	for _, tc := range []struct {
+		name string
+		req  domain.URLStatsRequest
+		want error
+	}{
+		{"unknown granularity", domain.URLStatsRequest{ShortCode: "abc123", Granularity: "minute"},
+			domain.ErrInvalidStatsRequest},
+		{"granularity case", domain.URLStatsRequest{ShortCode: "abc123", Granularity: "Day"},
+			domain.ErrInvalidStatsRequest},
+		{"from after to", domain.URLStatsRequest{ShortCode: "abc123", From: from, To: from.Add(-time.Hour)},
+			domain.ErrInvalidStatsRequest},
+		{"empty range", domain.URLStatsRequest{ShortCode: "abc123", From: from, To: from},
+			domain.ErrInvalidStatsRequest},
+		{"too many buckets", domain.URLStatsRequest{ShortCode: "abc123", Granularity: domain.StatsGranularityHour,
+			From: from, To: from.AddDate(1, 0, 0)}, domain.ErrInvalidStatsRequest},
+		{"unknown link", domain.URLStatsRequest{ShortCode: "missing"}, domain.ErrURLNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := service.GetURLStats(context.Background(), tc.req)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("GetURLStats = %+v, %v; want %v", result, err, tc.want)
+			}
+		})
+	}
+
+	unconfigured := newStatsService(nil)
+	if _, err := unconfigured.GetURLStats(context.Background(), domain.URLStatsRequest{ShortCode: "abc123"}); !errors.Is(err, errStatsUnavailable) {
+		t.Errorf("GetURLStats without a stats repository = %v, want %v", err, errStatsUnavailable)
+	}
+}
@@ -2,10 +2,10 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/metrics"
+	"reflect"
 	"strings"
 	"time"
 
@@ -13,25 +13,52 @@ import (
 
 	"github.com/umanagarjuna/go-url-shortener/internal/url/cache"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/domain"
-	"github.com/umanagarjuna/go-url-shortener/internal/url/events"
 	"github.com/umanagarjuna/go-url-shortener/internal/url/repository"
 	"github.com/umanagarjuna/go-url-shortener/pkg/shortcode"
+	"github.com/umanagarjuna/go-url-shortener/pkg/urlnorm"
 	"github.com/umanagarjuna/go-url-shortener/pkg/validator"
 )
 
 type URLService struct {
-	repo      repository.Repository // FIXED: Use interface instead of concrete type
-	cache     *cache.RedisCache
-	generator shortcode.Generator
-	validator validator.URLValidator
-	publisher *events.EventPublisher
-	logger    *zap.Logger
-	metrics   metrics.Metrics
-	baseURL   string
+	repo       repository.Repository // FIXED: Use interface instead of concrete type
+	cache      *cache.RedisCache
+	generator  shortcode.Generator
+	validator  validator.URLValidator
+	publisher  domain.EventPublisher
+	logger     *zap.Logger
+	metrics    metrics.Metrics
+	baseURL    string
+	normalizer *urlnorm.Normalizer
+	riskPolicy string
+	riskLimit  int
+	retention  time.Duration
+	stats      repository.StatsRepository
+	enrichers  []domain.ClickEnricher
 }
 
+// Policies for URLs whose deceptive-URL risk score reaches the threshold
+const (
+	RiskPolicyOff   = "off"
+	RiskPolicyFlag  = "flag"  // record the risk report in the URL's metadata
+	RiskPolicyBlock = "block" // reject the URL
+)
+
 type Config struct {
 	BaseURL string
+	// RiskPolicy is applied to URLs scoring at least RiskThreshold in
+	// validator.Analyze; off by default
+	RiskPolicy    string
+	RiskThreshold int
+	// PurgeRetention is how long soft-deleted URLs are kept before
+	// PurgeDeletedURLs removes them; 30 days by default
+	PurgeRetention time.Duration
+	// Normalizer canonicalizes URLs for dedupe; defaults to the lossless
+	// normalization only
+	Normalizer *urlnorm.Normalizer
+	// Stats serves GetURLStats; without it link statistics are unavailable
+	Stats repository.StatsRepository
+	// ClickEnrichers run in order on every click before it is published
+	ClickEnrichers []domain.ClickEnricher
 }
 
 func NewURLService(
@@ -39,20 +66,39 @@ func NewURLService(
 	cache *cache.RedisCache,
 	generator shortcode.Generator,
 	validator validator.URLValidator,
-	publisher *events.EventPublisher,
+	publisher domain.EventPublisher,
 	logger *zap.Logger,
 	metrics metrics.Metrics, // NEW
 	config Config,
 ) *URLService {
+	if config.Normalizer == nil {
+		config.Normalizer = urlnorm.New(urlnorm.Options{})
+	}
+	if config.RiskPolicy == "" {
+		config.RiskPolicy = RiskPolicyOff
+	}
+	if config.RiskThreshold <= 0 {
+		config.RiskThreshold = 60
+	}
+	if config.PurgeRetention <= 0 {
+		config.PurgeRetention = 30 * 24 * time.Hour
+	}
+
 	return &URLService{
-		repo:      repo,
-		cache:     cache,
-		generator: generator,
-		validator: validator,
-		publisher: publisher,
-		logger:    logger,
-		metrics:   metrics, // NEW
-		baseURL:   config.BaseURL,
+		repo:       repo,
+		cache:      cache,
+		generator:  generator,
+		validator:  validator,
+		publisher:  publisher,
+		logger:     logger,
+		metrics:    metrics, // NEW
+		baseURL:    config.BaseURL,
+		normalizer: config.Normalizer,
+		riskPolicy: config.RiskPolicy,
+		riskLimit:  config.RiskThreshold,
+		retention:  config.PurgeRetention,
+		stats:      config.Stats,
+		enrichers:  config.ClickEnrichers,
 	}
 }
 
@@ -63,9 +109,15 @@ func (s *URLService) CreateURL(ctx context.Context, req *domain.CreateURLRequest
 	}()
 
 	s.metrics.IncrementCounter("url_create_requests_total")
+
+	// Equivalent spellings of a URL share one canonical form, which keys
+	// both the response cache and the dedupe lookup
+	canonicalURL := s.canonicalize(req.URL)
+
 	// 1. Check response cache first
-	cacheKey := cache.GenerateResponseCacheKey(req.URL, req.UserID)
-	if cachedResponse, err := s.cache.GetResponse(ctx, cacheKey); err == nil && cachedResponse != nil {
+	cacheKey := cache.GenerateResponseCacheKey(canonicalURL, req.UserID)
+	if cachedResponse, err := s.cache.GetResponse(ctx, cacheKey); err == nil && cachedResponse != nil &&
+		(req.CustomAlias == "" || cachedResponse.ShortCode == req.CustomAlias) {
 		s.logger.Debug("Returning cached response",
 			zap.String("url", req.URL),
 			zap.Int64("user_id", req.UserID),
@@ -78,22 +130,35 @@ func (s *URLService) CreateURL(ctx context.Context, req *domain.CreateURLRequest
 		return nil, fmt.Errorf("URL validation failed: %w", err)
 	}
 
-	// 3. Check if URL is safe
-	safe, err := s.validator.IsSafe(req.URL)
-	if err != nil {
-		s.logger.Error("Failed to check URL safety",
-			zap.Error(err), zap.String("url", req.URL))
+	// 3. Validate custom alias if one was requested
+	if req.CustomAlias != "" {
+		if err := shortcode.ValidateAlias(req.CustomAlias); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidAlias, err)
+		}
+		if checker, ok := s.generator.(shortcode.AliasChecker); ok {
+			if err := checker.CheckAlias(req.CustomAlias); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidAlias, err)
+			}
+		}
 	}
-	if !safe {
-		return nil, fmt.Errorf("URL is not safe")
+
+	// 4. Check if URL is safe
+	if err := s.checkSafe(req.URL); err != nil {
+		return nil, err
 	}
 
-	// 4. Check for existing URL with detailed logging
+	riskMetadata, err := s.applyRiskPolicy(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	req = withMetadata(req, riskMetadata)
+
+	// 5. Check for existing URL with detailed logging
 	s.logger.Info("Checking for existing URL for user",
 		zap.String("url", req.URL),
 		zap.Int64("user_id", req.UserID))
 
-	existingURL, err := s.repo.GetByOriginalURLAndUser(ctx, req.URL, req.UserID)
+	existingURL, err := s.repo.GetByCanonicalURLAndUser(ctx, canonicalURL, req.UserID)
 	if err != nil {
 		s.metrics.IncrementCounter("url_create_errors_total")
 		s.logger.Error("Failed to check existing URL",
@@ -105,6 +170,12 @@ func (s *URLService) CreateURL(ctx context.Context, req *domain.CreateURLRequest
 
 	var response *domain.URLResponse
 
+	if existingURL != nil && req.CustomAlias != "" && existingURL.ShortCode != req.CustomAlias {
+		// The user already owns a short code for this URL; a second one would
+		// violate the per-user uniqueness of original URLs.
+		return nil, fmt.Errorf("%w as %s", domain.ErrURLAlreadyShortened, existingURL.ShortCode)
+	}
+
 	if existingURL != nil {
 		s.metrics.IncrementCounter("url_duplicates_prevented_total")
 		s.logger.Info("Found existing URL for user - returning same short code",
@@ -117,19 +188,25 @@ func (s *URLService) CreateURL(ctx context.Context, req *domain.CreateURLRequest
 
 		response = s.buildURLResponse(existingURL)
 	} else {
-		// 5. No existing URL - create new one
+		// 6. No existing URL - create new one
 		s.metrics.IncrementCounter("url_create_new_total")
 		s.logger.Info("No existing URL found for user - creating new one",
 			zap.String("url", req.URL),
 			zap.Int64("user_id", req.UserID))
 
-		response, err = s.createNewURLWithRetry(ctx, req)
+		redirectMetadata, err := s.resolveRedirects(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		req = withMetadata(req, redirectMetadata)
+
+		response, err = s.createNewURLWithRetry(ctx, req, canonicalURL)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// 6. Cache the response for future requests
+	// 7. Cache the response for future requests
 	if err := s.cache.SetResponse(ctx, cacheKey, response, 5*time.Minute); err != nil {
 		s.logger.Warn("Failed to cache response",
 			zap.Error(err),
@@ -139,14 +216,168 @@ func (s *URLService) CreateURL(ctx context.Context, req *domain.CreateURLRequest
 	return response, nil
 }
 
-func (s *URLService) createNewURLWithRetry(ctx context.Context, req *domain.CreateURLRequest) (*domain.URLResponse, error) {
+// ValidateURL runs the configured validator's checks on rawURL without
+// shortening it
+func (s *URLService) ValidateURL(ctx context.Context, rawURL string) (*domain.ValidationResult, error) {
+	s.metrics.IncrementCounter("url_validate_requests_total")
+
+	result := &domain.ValidationResult{URL: rawURL}
+
+	if err := s.validator.Validate(rawURL); err != nil {
+		result.Reason = err.Error()
+		var validationErr *validator.ValidationError
+		if errors.As(err, &validationErr) {
+			result.Rule = validationErr.Rule
+			result.Match = validationErr.Match
+		}
+		return result, nil
+	}
+	result.IsValid = true
+
+	safe, err := s.validator.IsSafe(rawURL)
+	var unsafeErr *validator.UnsafeURLError
+	if errors.As(err, &unsafeErr) {
+		result.Reason = unsafeErr.Error()
+		result.Rule = validator.RuleThreatList
+		result.Match = unsafeErr.Match.Expression
+		return result, nil
+	}
+	if err != nil {
+		s.logger.Error("Failed to check URL safety",
+			zap.Error(err), zap.String("url", rawURL))
+	}
+	if !safe {
+		result.Reason = "URL is not safe"
+		result.Rule = validator.RuleThreatList
+		return result, nil
+	}
+	result.IsSafe = true
+
+	return result, nil
+}
+
+// canonicalize returns the canonical form of rawURL, or rawURL itself if it
+// cannot be parsed (validation rejects it afterwards)
+func (s *URLService) canonicalize(rawURL string) string {
+	canonicalURL, err := s.normalizer.Normalize(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return canonicalURL
+}
+
+// checkSafe rejects URLs found on the threat lists
+func (s *URLService) checkSafe(rawURL string) error {
+	safe, err := s.validator.IsSafe(rawURL)
+	var unsafeErr *validator.UnsafeURLError
+	if errors.As(err, &unsafeErr) {
+		s.metrics.IncrementCounter("url_unsafe_rejected_total")
+		return fmt.Errorf("URL is not safe: %w", err)
+	}
+	if err != nil {
+		s.logger.Error("Failed to check URL safety",
+			zap.Error(err), zap.String("url", rawURL))
+	}
+	if !safe {
+		return fmt.Errorf("URL is not safe")
+	}
+	return nil
+}
+
+// resolveRedirects follows the redirect chain of rawURL, if the validator
+// supports it, and returns the final destination and chain as metadata
+func (s *URLService) resolveRedirects(ctx context.Context, rawURL string) (map[string]interface{}, error) {
+	follower, ok := s.validator.(validator.RedirectFollower)
+	if !ok {
+		return nil, nil
+	}
+
+	chain, err := follower.FollowRedirects(ctx, rawURL)
+	if err != nil {
+		s.metrics.IncrementCounter("url_redirect_rejected_total")
+		return nil, fmt.Errorf("redirect validation failed: %w", err)
+	}
+	if chain == nil {
+		return nil, nil
+	}
+
+	return map[string]interface{}{
+		"resolved_url":   chain.FinalURL,
+		"redirect_chain": chain.Hops,
+	}, nil
+}
+
+// applyRiskPolicy scores rawURL for deceptive presentation (homographs,
+// brand look-alikes, ...) and blocks it or returns the risk report as
+// metadata according to the configured policy
+func (s *URLService) applyRiskPolicy(rawURL string) (map[string]interface{}, error) {
+	if s.riskPolicy == RiskPolicyOff {
+		return nil, nil
+	}
+
+	report, err := s.validator.Analyze(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL validation failed: %w", err)
+	}
+	if report.Score < s.riskLimit {
+		return nil, nil
+	}
+
+	s.logger.Warn("Deceptive URL detected",
+		zap.String("url", rawURL),
+		zap.Int("risk_score", report.Score),
+		zap.Any("risk_reasons", report.Reasons),
+		zap.String("policy", s.riskPolicy))
+
+	if s.riskPolicy == RiskPolicyBlock {
+		s.metrics.IncrementCounter("url_deceptive_rejected_total")
+		return nil, &validator.DeceptiveURLError{Report: report}
+	}
+
+	s.metrics.IncrementCounter("url_deceptive_flagged_total")
+	return map[string]interface{}{
+		"risk_score":   report.Score,
+		"risk_reasons": report.Reasons,
+	}, nil
+}
+
+// derivedMetadataKeys are written by the service from the destination URL
+// and go stale when it is retargeted
+var derivedMetadataKeys = []string{"resolved_url", "redirect_chain", "risk_score", "risk_reasons"}
+
+// withMetadata returns a copy of req with extra merged into its metadata
+func withMetadata(req *domain.CreateURLRequest, extra map[string]interface{}) *domain.CreateURLRequest {
+	if len(extra) == 0 {
+		return req
+	}
+
+	metadata := make(map[string]interface{}, len(req.Metadata)+len(extra))
+	for key, value := range req.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range extra {
+		metadata[key] = value
+	}
+
+	updated := *req
+	updated.Metadata = metadata
+	return &updated
+}
+
+func (s *URLService) createNewURLWithRetry(ctx context.Context, req *domain.CreateURLRequest,
+	canonicalURL string) (*domain.URLResponse, error) {
 	maxRetries := 5
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		url, err := s.attemptCreateURL(ctx, req)
+		url, err := s.attemptCreateURL(ctx, req, canonicalURL)
 		if err != nil {
 			// Handle duplicate short code error
 			if isDuplicateShortCodeError(err) {
+				if req.CustomAlias != "" {
+					// A requested alias is taken; retrying would not help
+					return nil, fmt.Errorf("%w: %s", domain.ErrShortCodeConflict, req.CustomAlias)
+				}
+
 				s.logger.Warn("Duplicate short code detected, retrying",
 					zap.Int("attempt", attempt),
 					zap.Int("max_retries", maxRetries),
@@ -154,11 +385,16 @@ func (s *URLService) createNewURLWithRetry(ctx context.Context, req *domain.Crea
 
 				if attempt == maxRetries {
 					// Last attempt failed - try to find existing URL as fallback
-					return s.handleDuplicateErrorFallback(ctx, req)
+					return s.handleDuplicateErrorFallback(ctx, req, canonicalURL)
 				}
 				continue // Retry with new short code
 			}
 
+			// A concurrent request created the same canonical URL first
+			if isDuplicateUserURLError(err) {
+				return s.handleDuplicateErrorFallback(ctx, req, canonicalURL)
+			}
+
 			// Other errors (validation, database, etc.)
 			return nil, fmt.Errorf("failed to create URL on attempt %d: %w", attempt, err)
 		}
@@ -176,20 +412,27 @@ func (s *URLService) createNewURLWithRetry(ctx context.Context, req *domain.Crea
 	return nil, fmt.Errorf("unexpected error: should not reach here")
 }
 
-func (s *URLService) attemptCreateURL(ctx context.Context, req *domain.CreateURLRequest) (*domain.URLResponse, error) {
-	// Generate unique short code
-	shortCode, err := s.generateUniqueShortCode(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate short code: %w", err)
+func (s *URLService) attemptCreateURL(ctx context.Context, req *domain.CreateURLRequest,
+	canonicalURL string) (*domain.URLResponse, error) {
+	// Use the requested alias, otherwise generate a unique short code.
+	// Codes derived from the row ID are assigned by the repository on insert.
+	shortCode := req.CustomAlias
+	if _, derived := shortcode.Unwrap(s.generator).(shortcode.IDEncoder); shortCode == "" && !derived {
+		var err error
+		shortCode, err = s.generateUniqueShortCode(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate short code: %w", err)
+		}
 	}
 
 	// Create URL entity
 	url := &domain.URL{
-		ShortCode:   shortCode,
-		OriginalURL: req.URL,
-		UserID:      req.UserID, // FIXED: Direct assignment (not pointer)
-		IsActive:    true,
-		ClickCount:  0,
+		ShortCode:    shortCode,
+		OriginalURL:  req.URL,
+		CanonicalURL: canonicalURL,
+		UserID:       req.UserID, // FIXED: Direct assignment (not pointer)
+		IsActive:     true,
+		ClickCount:   0,
 	}
 
 	// Set expiration if provided
@@ -206,30 +449,32 @@ func (s *URLService) attemptCreateURL(ctx context.Context, req *domain.CreateURL
 		}
 	}
 
-	// Save to database
-	if err := s.repo.Create(ctx, url); err != nil {
+	// Save to database together with the created event
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, url); err != nil {
+			return err
+		}
+		return s.publisher.PublishURLCreated(ctx, url)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Cache and publish events (non-blocking)
 	if err := s.cache.Set(ctx, url); err != nil {
 		s.logger.Warn("Failed to cache URL", zap.Error(err))
 	}
 
-	if err := s.publisher.PublishURLCreated(ctx, url); err != nil {
-		s.logger.Error("Failed to publish URL created event", zap.Error(err))
-	}
-
 	return s.buildURLResponse(url), nil
 }
 
-func (s *URLService) handleDuplicateErrorFallback(ctx context.Context, req *domain.CreateURLRequest) (*domain.URLResponse, error) {
+func (s *URLService) handleDuplicateErrorFallback(ctx context.Context, req *domain.CreateURLRequest,
+	canonicalURL string) (*domain.URLResponse, error) {
 	s.logger.Warn("All retry attempts failed, checking for existing URL as fallback",
 		zap.String("url", req.URL),
 		zap.Int64("user_id", req.UserID))
 
 	// Try to find existing URL one more time
-	existingURL, err := s.repo.GetByOriginalURLAndUser(ctx, req.URL, req.UserID)
+	existingURL, err := s.repo.GetByCanonicalURLAndUser(ctx, canonicalURL, req.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create URL after retries and failed to find existing URL: %w", err)
 	}
@@ -244,6 +489,12 @@ func (s *URLService) handleDuplicateErrorFallback(ctx context.Context, req *doma
 }
 
 func (s *URLService) generateUniqueShortCode(ctx context.Context) (string, error) {
+	// Collision-free generators don't need the lookup loop below; a clash
+	// with a custom alias is still caught by the unique index on insert.
+	if unique, ok := s.generator.(shortcode.UniqueGenerator); ok && unique.Unique() {
+		return s.generator.Generate()
+	}
+
 	maxRetries := 10
 
 	for i := 0; i < maxRetries; i++ {
@@ -274,12 +525,13 @@ func (s *URLService) generateUniqueShortCode(ctx context.Context) (string, error
 
 func (s *URLService) buildURLResponse(url *domain.URL) *domain.URLResponse {
 	return &domain.URLResponse{
-		ShortCode:   url.ShortCode,
-		ShortURL:    fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
-		OriginalURL: url.OriginalURL,
-		CreatedAt:   url.CreatedAt,
-		ExpiresAt:   url.ExpiresAt,
-		ClickCount:  url.ClickCount,
+		ShortCode:     url.ShortCode,
+		ShortURL:      fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
+		OriginalURL:   url.OriginalURL,
+		CreatedAt:     url.CreatedAt,
+		ExpiresAt:     url.ExpiresAt,
+		ClickCount:    url.ClickCount,
+		BotClickCount: url.BotClickCount,
 	}
 }
 
@@ -303,7 +555,8 @@ func isDuplicateUserURLError(err error) bool {
 	errMsg := strings.ToLower(err.Error())
 	return strings.Contains(errMsg, "duplicate key value violates unique constraint") &&
 		(strings.Contains(errMsg, "urls_user_url_key") ||
-			strings.Contains(errMsg, "idx_urls_user_url"))
+			strings.Contains(errMsg, "idx_urls_user_url") ||
+			strings.Contains(errMsg, "idx_urls_user_canonical_url"))
 }
 
 func (s *URLService) GetURL(ctx context.Context, shortCode string) (*domain.URLResponse, error) {
@@ -342,12 +595,13 @@ func (s *URLService) GetURL(ctx context.Context, shortCode string) (*domain.URLR
 	}
 
 	return &domain.URLResponse{
-		ShortCode:   url.ShortCode,
-		ShortURL:    fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
-		OriginalURL: url.OriginalURL,
-		CreatedAt:   url.CreatedAt,
-		ExpiresAt:   url.ExpiresAt,
-		ClickCount:  url.ClickCount,
+		ShortCode:     url.ShortCode,
+		ShortURL:      fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
+		OriginalURL:   url.OriginalURL,
+		CreatedAt:     url.CreatedAt,
+		ExpiresAt:     url.ExpiresAt,
+		ClickCount:    url.ClickCount,
+		BotClickCount: url.BotClickCount,
 	}, nil
 }
 
@@ -360,68 +614,429 @@ func (s *URLService) RedirectURL(ctx context.Context, shortCode string,
 		return "", err
 	}
 	if urlResp == nil {
-		return "", fmt.Errorf("URL not found")
+		return "", domain.ErrURLNotFound
 	}
 
 	// Increment click count asynchronously
 	go func() {
-		ctx := context.Background()
-		if err := s.repo.IncrementClickCount(ctx, shortCode); err != nil {
-			s.logger.Error("Failed to increment click count",
-				zap.Error(err), zap.String("short_code", shortCode))
-		}
-
-		// Publish click event
+		// Outlive the request but keep its trace context for the event
+		ctx := context.WithoutCancel(ctx)
 		clickEvent.ShortCode = shortCode
 		clickEvent.Timestamp = time.Now()
-		if err := s.publisher.PublishURLClicked(ctx, clickEvent); err != nil {
-			s.logger.Error("Failed to publish click event",
-				zap.Error(err), zap.String("short_code", shortCode))
-		}
+		s.recordClick(ctx, clickEvent)
 	}()
 
 	return urlResp.OriginalURL, nil
 }
 
+// recordClick enriches a click, counts it as a human or a bot click and
+// publishes it. Publishing is attempted even when counting fails; the
+// counting error is returned.
+func (s *URLService) recordClick(ctx context.Context, clickEvent *domain.ClickEvent) error {
+	for _, enricher := range s.enrichers {
+		enricher.EnrichClick(ctx, clickEvent)
+	}
+
+	increment := s.repo.IncrementClickCount
+	if clickEvent.IsBot {
+		increment = s.repo.IncrementBotClickCount
+		reason, _, _ := strings.Cut(clickEvent.BotReason, ":")
+		s.metrics.IncrementCounterWithLabels("url_bot_clicks_total", map[string]string{"reason": reason})
+	}
+
+	countErr := increment(ctx, clickEvent.ShortCode)
+	if countErr != nil {
+		s.logger.Error("Failed to increment click count",
+			zap.Error(countErr),
+			zap.String("short_code", clickEvent.ShortCode),
+			zap.Bool("is_bot", clickEvent.IsBot))
+	} else {
+		s.logger.Debug("Successfully incremented click count",
+			zap.String("short_code", clickEvent.ShortCode),
+			zap.Bool("is_bot", clickEvent.IsBot))
+	}
+
+	if err := s.publisher.PublishURLClicked(ctx, clickEvent); err != nil {
+		s.logger.Error("Failed to publish URL clicked event",
+			zap.Error(err), zap.String("short_code", clickEvent.ShortCode))
+	} else {
+		s.logger.Debug("Successfully published URL clicked event",
+			zap.String("short_code", clickEvent.ShortCode))
+	}
+
+	return countErr
+}
+
+// UpdateURL applies a partial update to the URL with the given short code.
+// A new destination goes through the same checks as CreateURL.
+func (s *URLService) UpdateURL(ctx context.Context, shortCode string,
+	req *domain.UpdateURLRequest) (*domain.URLResponse, error) {
+
+	url, err := s.repo.GetByShortCodeIncludingInactive(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL from repository: %w", err)
+	}
+	if url == nil {
+		return nil, domain.ErrURLNotFound
+	}
+
+	previous := *url
+	var updatedFields []string
+
+	metadata := url.Metadata
+	if req.Metadata != nil {
+		metadata = domain.JSONB(req.Metadata)
+	}
+
+	if req.OriginalURL != nil && *req.OriginalURL != url.OriginalURL {
+		derived, err := s.checkRetarget(ctx, url, *req.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+
+		retargeted := make(domain.JSONB, len(metadata)+len(derived))
+		for key, value := range metadata {
+			retargeted[key] = value
+		}
+		for _, key := range derivedMetadataKeys {
+			delete(retargeted, key)
+		}
+		for key, value := range derived {
+			retargeted[key] = value
+		}
+		metadata = retargeted
+
+		url.OriginalURL = *req.OriginalURL
+		url.CanonicalURL = s.canonicalize(*req.OriginalURL)
+		updatedFields = append(updatedFields, "original_url")
+	}
+
+	switch {
+	case req.ClearExpiresAt:
+		if url.ExpiresAt != nil {
+			url.ExpiresAt = nil
+			updatedFields = append(updatedFields, "expires_at")
+		}
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidUpdate)
+		}
+		if url.ExpiresAt == nil || !url.ExpiresAt.Equal(*req.ExpiresAt) {
+			url.ExpiresAt = req.ExpiresAt
+			updatedFields = append(updatedFields, "expires_at")
+		}
+	}
+
+	if !reflect.DeepEqual(metadata, url.Metadata) {
+		url.Metadata = metadata
+		updatedFields = append(updatedFields, "metadata")
+	}
+
+	if req.IsActive != nil && *req.IsActive != url.IsActive {
+		url.IsActive = *req.IsActive
+		updatedFields = append(updatedFields, "is_active")
+	}
+
+	if len(updatedFields) == 0 {
+		return s.buildURLResponse(url), nil
+	}
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, url); err != nil {
+			return err
+		}
+		return s.publisher.PublishURLUpdated(ctx, url, updatedFields)
+	})
+	if err != nil {
+		if isDuplicateUserURLError(err) {
+			return nil, domain.ErrURLAlreadyShortened
+		}
+		return nil, fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	s.metrics.IncrementCounter("url_update_total")
+	s.logger.Info("Updated URL",
+		zap.String("short_code", shortCode),
+		zap.Strings("updated_fields", updatedFields))
+
+	s.invalidateCache(ctx, &previous)
+	if url.CanonicalURL != previous.CanonicalURL {
+		s.invalidateCache(ctx, url)
+	}
+
+	return s.buildURLResponse(url), nil
+}
+
+// GetURLHistory returns the revisions of a URL, newest first
+func (s *URLService) GetURLHistory(ctx context.Context, shortCode string) ([]*domain.URLRevision, error) {
+	revisions, err := s.repo.GetRevisions(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL history: %w", err)
+	}
+	if len(revisions) == 0 {
+		return nil, domain.ErrURLNotFound
+	}
+
+	return revisions, nil
+}
+
+// RollbackURL restores the state recorded in a prior revision through
+// UpdateURL, so the rollback is validated and recorded like any update
+func (s *URLService) RollbackURL(ctx context.Context, shortCode string,
+	revision int) (*domain.URLResponse, error) {
+
+	target, err := s.repo.GetRevision(ctx, shortCode, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL revision: %w", err)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: %s revision %d", domain.ErrRevisionNotFound, shortCode, revision)
+	}
+
+	snapshot := target.After
+	req := &domain.UpdateURLRequest{
+		OriginalURL: &snapshot.OriginalURL,
+		IsActive:    &snapshot.IsActive,
+		Metadata:    map[string]interface{}(snapshot.Metadata),
+	}
+	if req.Metadata == nil {
+		req.Metadata = map[string]interface{}{}
+	}
+	if snapshot.ExpiresAt != nil {
+		req.ExpiresAt = snapshot.ExpiresAt
+	} else {
+		req.ClearExpiresAt = true
+	}
+
+	s.logger.Info("Rolling back URL",
+		zap.String("short_code", shortCode),
+		zap.Int("revision", revision))
+
+	return s.UpdateURL(ctx, shortCode, req)
+}
+
+// checkRetarget validates a new destination for url and returns the
+// metadata derived from it
+func (s *URLService) checkRetarget(ctx context.Context, url *domain.URL,
+	originalURL string) (map[string]interface{}, error) {
+
+	if err := s.validator.Validate(originalURL); err != nil {
+		return nil, fmt.Errorf("URL validation failed: %w", err)
+	}
+	if err := s.checkSafe(originalURL); err != nil {
+		return nil, err
+	}
+
+	// The user may own only one short code per canonical URL
+	canonicalURL := s.canonicalize(originalURL)
+	if canonicalURL != url.CanonicalURL {
+		existing, err := s.repo.GetByCanonicalURLAndUser(ctx, canonicalURL, url.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot verify existing URLs: %w", err)
+		}
+		if existing != nil && existing.ShortCode != url.ShortCode {
+			return nil, fmt.Errorf("%w as %s", domain.ErrURLAlreadyShortened, existing.ShortCode)
+		}
+	}
+
+	derived, err := s.applyRiskPolicy(originalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectMetadata, err := s.resolveRedirects(ctx, originalURL)
+	if err != nil {
+		return nil, err
+	}
+	if derived == nil {
+		derived = redirectMetadata
+	} else {
+		for key, value := range redirectMetadata {
+			derived[key] = value
+		}
+	}
+
+	return derived, nil
+}
+
+// invalidateCache drops the cached URL and the cached create response for
+// its canonical URL
+func (s *URLService) invalidateCache(ctx context.Context, url *domain.URL) {
+	if err := s.cache.Delete(ctx, url.ShortCode); err != nil {
+		s.logger.Warn("Failed to delete URL from cache",
+			zap.Error(err), zap.String("short_code", url.ShortCode))
+	}
+
+	canonicalURL := url.CanonicalURL
+	if canonicalURL == "" {
+		canonicalURL = s.canonicalize(url.OriginalURL)
+	}
+
+	responseCacheKey := cache.GenerateResponseCacheKey(canonicalURL, url.UserID)
+	if err := s.cache.DeleteResponse(ctx, responseCacheKey); err != nil {
+		s.logger.Warn("Failed to delete response cache",
+			zap.Error(err),
+			zap.String("response_cache_key", responseCacheKey))
+	}
+}
+
 // FIXED: Remove userID parameter to match interface
 func (s *URLService) DeleteURL(ctx context.Context, shortCode string) error {
+	return s.deleteURL(ctx, shortCode, domain.DeleteReasonUserDelete)
+}
+
+// TakedownURL deletes a URL reported for abuse
+func (s *URLService) TakedownURL(ctx context.Context, shortCode string) error {
+	return s.deleteURL(ctx, shortCode, domain.DeleteReasonAbuseTakedown)
+}
+
+// deleteURL soft-deletes a URL, active or not, and publishes url.deleted
+// with the reason
+func (s *URLService) deleteURL(ctx context.Context, shortCode, reason string) error {
 	// Get URL first
-	url, err := s.repo.GetByShortCode(ctx, shortCode)
+	url, err := s.repo.GetByShortCodeIncludingInactive(ctx, shortCode)
 	if err != nil {
 		return err
 	}
 	if url == nil {
-		return fmt.Errorf("URL not found")
+		return domain.ErrURLNotFound
 	}
 
-	// Delete from database
-	if err := s.repo.Delete(ctx, shortCode); err != nil {
+	// Delete from database together with the deleted event
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, shortCode); err != nil {
+			return err
+		}
+		url.IsActive = false
+		url.DeletedAt = time.Now()
+		return s.publisher.PublishURLDeleted(ctx, url, reason)
+	})
+	if err != nil {
 		return err
 	}
 
-	// Remove URL cache
-	if err := s.cache.Delete(ctx, shortCode); err != nil {
-		s.logger.Warn("Failed to delete URL from cache", zap.Error(err))
+	s.metrics.IncrementCounterWithLabels("url_deleted_total", map[string]string{"reason": reason})
+	s.logger.Info("Deleted URL",
+		zap.String("short_code", shortCode),
+		zap.String("reason", reason))
+
+	// Remove URL and response cache entries
+	s.invalidateCache(ctx, url)
+
+	return nil
+}
+
+// RestoreURL undoes the soft delete of a URL unless it has expired or the
+// user has since shortened the same URL again. The short code itself can't
+// have been reused: urls.short_code is unique across deleted rows too.
+func (s *URLService) RestoreURL(ctx context.Context, shortCode string) (*domain.URLResponse, error) {
+	url, err := s.repo.GetDeletedByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted URL: %w", err)
+	}
+	if url == nil {
+		return nil, domain.ErrURLNotFound
 	}
 
-	// ACTUALLY DELETE response cache (not just log)
-	data := fmt.Sprintf("%s:%d", url.OriginalURL, url.UserID)
-	hash := md5.Sum([]byte(data))
-	cacheKey := hex.EncodeToString(hash[:])
+	// A restored expired URL would stay unreachable until the expiry sweep
+	// deleted it again
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: %s expired at %s", domain.ErrURLExpired,
+			shortCode, url.ExpiresAt.Format(time.RFC3339))
+	}
 
-	// Delete the response cache key directly
-	responseCacheKey := fmt.Sprintf("response:%s", cacheKey)
-	if err := s.cache.Delete(ctx, responseCacheKey); err != nil {
-		s.logger.Warn("Failed to delete response cache",
-			zap.Error(err),
-			zap.String("response_cache_key", responseCacheKey))
-	} else {
-		s.logger.Info("Successfully invalidated response cache",
-			zap.String("short_code", shortCode),
-			zap.String("response_cache_key", responseCacheKey))
+	existing, err := s.repo.GetByCanonicalURLAndUser(ctx, url.CanonicalURL, url.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify existing URLs: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w as %s", domain.ErrURLAlreadyShortened, existing.ShortCode)
 	}
 
-	return nil
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, url); err != nil {
+			return err
+		}
+		return s.publisher.PublishURLRestored(ctx, url)
+	})
+	if err != nil {
+		if isDuplicateUserURLError(err) {
+			return nil, domain.ErrURLAlreadyShortened
+		}
+		return nil, fmt.Errorf("failed to restore URL: %w", err)
+	}
+
+	s.metrics.IncrementCounter("url_restored_total")
+	s.logger.Info("Restored URL", zap.String("short_code", shortCode))
+
+	s.invalidateCache(ctx, url)
+
+	return s.buildURLResponse(url), nil
+}
+
+// purgeBatchSize bounds how many rows one purge statement deletes
+const purgeBatchSize = 500
+
+// PurgeDeletedURLs hard-deletes URLs soft-deleted longer ago than the
+// retention window and returns how many were removed
+func (s *URLService) PurgeDeletedURLs(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	purged := 0
+
+	for {
+		var urls []*domain.URL
+		err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			urls, err = s.repo.PurgeDeleted(ctx, cutoff, purgeBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, url := range urls {
+				if err := s.publisher.PublishURLPurged(ctx, url); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		for range urls {
+			s.metrics.IncrementCounter("url_purged_total")
+		}
+
+		purged += len(urls)
+		if len(urls) < purgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged deleted URLs",
+			zap.Int("count", purged),
+			zap.Time("deleted_before", cutoff))
+	}
+
+	return purged, nil
+}
+
+// RunPurgeJob calls PurgeDeletedURLs every interval until ctx is done
+func (s *URLService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeDeletedURLs(ctx); err != nil {
+				s.logger.Error("Failed to purge deleted URLs", zap.Error(err))
+			}
+		}
+	}
 }
 
 // FIXED: Use correct repository method name
@@ -436,12 +1051,13 @@ func (s *URLService) GetUserURLs(ctx context.Context, userID int64,
 	responses := make([]*domain.URLResponse, len(urls))
 	for i, url := range urls {
 		responses[i] = &domain.URLResponse{
-			ShortCode:   url.ShortCode,
-			ShortURL:    fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
-			OriginalURL: url.OriginalURL,
-			CreatedAt:   url.CreatedAt,
-			ExpiresAt:   url.ExpiresAt,
-			ClickCount:  url.ClickCount,
+			ShortCode:     url.ShortCode,
+			ShortURL:      fmt.Sprintf("%s/%s", s.baseURL, url.ShortCode),
+			OriginalURL:   url.OriginalURL,
+			CreatedAt:     url.CreatedAt,
+			ExpiresAt:     url.ExpiresAt,
+			ClickCount:    url.ClickCount,
+			BotClickCount: url.BotClickCount,
 		}
 	}
 
@@ -489,19 +1105,9 @@ func (s *URLService) GetURLAndIncrementClick(ctx context.Context, shortCode, use
 
 	// Increment click count and publish event (async to not slow down redirect)
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
 		defer cancel()
 
-		// Increment click count in database
-		if err := s.repo.IncrementClickCount(ctx, shortCode); err != nil {
-			s.logger.Error("Failed to increment click count",
-				zap.Error(err), zap.String("short_code", shortCode))
-		} else {
-			s.logger.Debug("Successfully incremented click count",
-				zap.String("short_code", shortCode))
-		}
-
-		// Publish click event to Kafka
 		clickEvent := &domain.ClickEvent{
 			ShortCode: shortCode,
 			UserAgent: userAgent,
@@ -509,18 +1115,17 @@ func (s *URLService) GetURLAndIncrementClick(ctx context.Context, shortCode, use
 			Referrer:  referrer,
 			Timestamp: time.Now(),
 		}
-
-		if err := s.publisher.PublishURLClicked(ctx, clickEvent); err != nil {
-			s.logger.Error("Failed to publish URL clicked event",
-				zap.Error(err), zap.String("short_code", shortCode))
-		} else {
-			s.logger.Debug("Successfully published URL clicked event",
-				zap.String("short_code", shortCode))
+		if err := s.recordClick(ctx, clickEvent); err != nil {
+			return
 		}
 
 		// Update cache with incremented count (for consistency)
 		updatedURL := *url
-		updatedURL.ClickCount++
+		if clickEvent.IsBot {
+			updatedURL.BotClickCount++
+		} else {
+			updatedURL.ClickCount++
+		}
 		if err := s.cache.Set(ctx, &updatedURL); err != nil {
 			s.logger.Warn("Failed to update cache with new click count",
 				zap.Error(err), zap.String("short_code", shortCode))
@@ -0,0 +1,297 @@
+// Package botdetect classifies requests as automated from their
+// User-Agent, source IP and request rate.
+package botdetect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/reloader"
+)
+
+// Reason prefixes of a Verdict
+const (
+	ReasonUserAgent      = "user_agent"
+	ReasonEmptyUserAgent = "empty_user_agent"
+	ReasonIPRange        = "ip_range"
+	ReasonRate           = "rate"
+)
+
+// defaultSignatures are User-Agent substrings of link-preview bots,
+// crawlers, scanners, uptime checkers and HTTP libraries. Matching is
+// case-insensitive.
+var defaultSignatures = []string{
+	// Link previews
+	"facebookexternalhit", "facebot", "twitterbot", "slackbot", "slack-imgproxy",
+	"linkedinbot", "discordbot", "telegrambot", "whatsapp", "skypeuripreview",
+	"pinterestbot", "redditbot", "embedly", "iframely", "vkshare", "bitlybot",
+	// Crawlers
+	"googlebot", "google-inspectiontool", "adsbot-google", "bingbot", "bingpreview",
+	"baiduspider", "yandexbot", "duckduckbot", "applebot", "yahoo! slurp",
+	"ahrefsbot", "semrushbot", "petalbot", "bytespider", "gptbot", "claudebot",
+	"amazonbot", "mj12bot", "dotbot", "ccbot",
+	// Scanners and link checkers
+	"urlscan", "virustotal", "safebrowsing", "nessus", "nmap", "zgrab", "masscan",
+	"qualys", "censys", "shodan", "expanse", "linkchecker", "w3c_validator",
+	// Uptime checkers
+	"pingdom", "uptimerobot", "statuscake", "site24x7", "betteruptime",
+	"datadog", "newrelicpinger", "check_http", "freshping",
+	// HTTP libraries and headless browsers
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "go-http-client",
+	"okhttp", "java/", "apache-httpclient", "libwww-perl", "node-fetch", "axios/",
+	"postmanruntime", "httpie", "headlesschrome", "phantomjs", "puppeteer",
+	"playwright", "selenium",
+}
+
+// genericPattern catches self-declared bots missing from the signatures;
+// genericExceptions are look-alikes such as the Cubot phone brand
+var (
+	genericPattern    = regexp.MustCompile(`(?i)\b[a-z0-9_-]*(?:bot|crawler|spider)\b`)
+	genericExceptions = regexp.MustCompile(`(?i)\bcubot\b`)
+)
+
+type Config struct {
+	// Signatures are extra case-insensitive User-Agent substrings
+	Signatures []string
+	// RangesFile lists crawler IP ranges, one CIDR or address per line,
+	// optionally followed by a label; "#" starts a comment
+	RangesFile string
+	// RefreshInterval is how often RangesFile is checked for changes
+	RefreshInterval time.Duration
+	// RateLimit is the number of hits per IP within RateWindow above which
+	// further hits count as automated; 0 disables the heuristic
+	RateLimit  int
+	RateWindow time.Duration // defaults to one second
+}
+
+// Verdict is the classification of one request. Reason is one of the
+// Reason constants, followed by ":" and the matching signature or range
+// label where there is one.
+type Verdict struct {
+	IsBot  bool
+	Reason string
+}
+
+// Detector classifies requests. It is safe for concurrent use.
+type Detector struct {
+	config     Config
+	signatures []string
+	ranges     atomic.Pointer[rangeIndex]
+	rates      *rateCounter
+}
+
+// rangeIndex maps prefix length to the masked prefixes of that length and
+// their labels, so a lookup costs one map probe per distinct length
+type rangeIndex map[int]map[netip.Prefix]string
+
+func New(config Config) (*Detector, error) {
+	if config.RateWindow <= 0 {
+		config.RateWindow = time.Second
+	}
+
+	d := &Detector{
+		config: config,
+		rates:  newRateCounter(config.RateWindow),
+	}
+	for _, signature := range slices.Concat(defaultSignatures, config.Signatures) {
+		d.signatures = append(d.signatures, strings.ToLower(signature))
+	}
+
+	d.ranges.Store(&rangeIndex{})
+	if config.RangesFile != "" {
+		if err := d.Reload(); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// Reload re-reads the ranges file. On error the previous ranges stay in
+// use.
+func (d *Detector) Reload() error {
+	data, err := os.ReadFile(d.config.RangesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read bot IP ranges %s: %w", d.config.RangesFile, err)
+	}
+
+	index := rangeIndex{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		prefix, err := parsePrefix(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid bot IP range on line %d of %s: %w",
+				line, d.config.RangesFile, err)
+		}
+
+		label := fields[0]
+		if len(fields) > 1 {
+			label = strings.Join(fields[1:], " ")
+		}
+		if index[prefix.Bits()] == nil {
+			index[prefix.Bits()] = make(map[netip.Prefix]string)
+		}
+		index[prefix.Bits()][prefix] = label
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read bot IP ranges %s: %w", d.config.RangesFile, err)
+	}
+
+	d.ranges.Store(&index)
+	return nil
+}
+
+// Watch reloads the ranges file when it changes or on SIGHUP until ctx is
+// cancelled. Reload errors are passed to onError.
+func (d *Detector) Watch(ctx context.Context, onError func(error)) {
+	if d.config.RangesFile == "" {
+		return
+	}
+
+	reloader.Watch(ctx, d.config.RefreshInterval, []string{d.config.RangesFile}, func() {
+		if err := d.Reload(); err != nil {
+			onError(err)
+		}
+	})
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// Classify decides whether a request from ip with the given User-Agent is
+// automated. Every call counts towards the IP's rate, so call it once per
+// request.
+func (d *Detector) Classify(ip, userAgent string) Verdict {
+	addr, err := netip.ParseAddr(ip)
+	if err == nil {
+		addr = addr.Unmap()
+	}
+
+	// Count the hit first so bursts are tracked whatever else matches
+	overLimit := err == nil && d.config.RateLimit > 0 &&
+		d.rates.hit(addr, time.Now()) > d.config.RateLimit
+
+	if strings.TrimSpace(userAgent) == "" {
+		return Verdict{IsBot: true, Reason: ReasonEmptyUserAgent}
+	}
+	if signature, ok := d.matchUserAgent(userAgent); ok {
+		return Verdict{IsBot: true, Reason: ReasonUserAgent + ":" + signature}
+	}
+	if err == nil {
+		if label, ok := d.matchRange(addr); ok {
+			return Verdict{IsBot: true, Reason: ReasonIPRange + ":" + label}
+		}
+	}
+	if overLimit {
+		return Verdict{IsBot: true, Reason: ReasonRate}
+	}
+
+	return Verdict{}
+}
+
+func (d *Detector) matchUserAgent(userAgent string) (string, bool) {
+	lower := strings.ToLower(userAgent)
+	for _, signature := range d.signatures {
+		if strings.Contains(lower, signature) {
+			return signature, true
+		}
+	}
+
+	if match := genericPattern.FindString(userAgent); match != "" &&
+		!genericExceptions.MatchString(match) {
+		return strings.ToLower(match), true
+	}
+
+	return "", false
+}
+
+func (d *Detector) matchRange(addr netip.Addr) (string, bool) {
+	for bits, prefixes := range *d.ranges.Load() {
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue // IPv6 length for an IPv4 address
+		}
+		if label, ok := prefixes[prefix]; ok {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// rateCounter counts hits per IP in fixed windows
+type rateCounter struct {
+	mu        sync.Mutex
+	window    time.Duration
+	counts    map[netip.Addr]*rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	hits  int
+}
+
+func newRateCounter(window time.Duration) *rateCounter {
+	return &rateCounter{
+		window: window,
+		counts: make(map[netip.Addr]*rateWindow),
+	}
+}
+
+// hit records a hit and returns the hits of addr in the current window
+func (c *rateCounter) hit(addr netip.Addr, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Forget idle IPs now and then so the map stays bounded
+	if now.Sub(c.lastSweep) > 10*c.window {
+		for a, w := range c.counts {
+			if now.Sub(w.start) > c.window {
+				delete(c.counts, a)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	w, ok := c.counts[addr]
+	if !ok || now.Sub(w.start) >= c.window {
+		w = &rateWindow{start: now}
+		c.counts[addr] = w
+	}
+	w.hits++
+
+	return w.hits
+}
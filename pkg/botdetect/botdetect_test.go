@@ -0,0 +1,216 @@
+package botdetect
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const browserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
+func TestClassifyUserAgent(t *testing.T) {
+	d, err := New(Config{Signatures: []string{"ExampleMonitor"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		userAgent string
+		reason    string // "" for a human
+	}{
+		{"browser", browserAgent, ""},
+		{"empty", "", ReasonEmptyUserAgent},
+		{"blank", " \t", ReasonEmptyUserAgent},
+
+		// Signatures, case-insensitive
+		{"Googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "user_agent:googlebot"},
+		{"link preview", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", "user_agent:facebookexternalhit"},
+		{"Slack", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", "user_agent:slackbot"},
+		{"curl", "curl/8.4.0", "user_agent:curl/"},
+		{"HTTP library", "Python-Requests/2.31.0", "user_agent:python-requests"},
+		{"headless", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/124.0.0.0 Safari/537.36", "user_agent:headlesschrome"},
+		{"configured signature", "examplemonitor/2.0", "user_agent:examplemonitor"},
+
+		// Self-declared bots missing from the signatures
+		{"generic bot", "Mozilla/5.0 (compatible; NewsFetchBot/1.0)", "user_agent:newsfetchbot"},
+		{"generic crawler", "Acme-Crawler/3.1", "user_agent:acme-crawler"},
+		{"generic spider", "my_spider", "user_agent:my_spider"},
+
+		// Look-alikes
+		{"Cubot phone", "Mozilla/5.0 (Linux; Android 12; CUBOT P60 Build/SP1A.210812.016) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36", ""},
+		{"bot inside a word", "Mozilla/5.0 (Linux; Android 13; RoboticsTab) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36", ""},
+		{"bot prefix", "AbbottBrowser/1.0", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := d.Classify("203.0.113.10", tc.userAgent)
+			want := Verdict{IsBot: tc.reason != "", Reason: tc.reason}
+			if got != want {
+				t.Errorf("Classify(%q) = %+v, want %+v", tc.userAgent, got, want)
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"192.0.2.0/24", "192.0.2.0/24"},
+		{"192.0.2.77/24", "192.0.2.0/24"}, // masked
+		{"192.0.2.77", "192.0.2.77/32"},
+		{"2001:db8::/32", "2001:db8::/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+
+		// 4-in-6 forms become IPv4, so they match unmapped client IPs
+		{"::ffff:192.0.2.77", "192.0.2.77/32"},
+		{"::ffff:192.0.2.0/120", "192.0.2.0/24"},
+		{"::ffff:192.0.2.77/120", "192.0.2.0/24"},
+		{"::ffff:0.0.0.0/96", "0.0.0.0/0"},
+	} {
+		got, err := parsePrefix(tc.in)
+		if err != nil || got.String() != tc.want {
+			t.Errorf("parsePrefix(%q) = %v, %v; want %s", tc.in, got, err, tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "crawler", "192.0.2.0/33", "2001:db8::/129", "192.0.2.256"} {
+		if _, err := parsePrefix(in); err == nil {
+			t.Errorf("parsePrefix(%q) succeeded", in)
+		}
+	}
+}
+
+func writeRanges(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClassifyIPRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.txt")
+	writeRanges(t, path, `
+# Crawler ranges
+66.249.64.0/19   Googlebot
+2001:4860:4801::/48 Googlebot
+::ffff:157.55.39.0/120 Bing Preview   # 4-in-6
+198.51.100.7
+`)
+
+	d, err := New(Config{RangesFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		ip     string
+		reason string
+	}{
+		{"66.249.66.1", "ip_range:Googlebot"},
+		{"66.249.95.255", "ip_range:Googlebot"},
+		{"66.249.96.0", ""},
+		{"::ffff:66.249.66.1", "ip_range:Googlebot"}, // mapped client address
+		{"2001:4860:4801:10::1", "ip_range:Googlebot"},
+		{"2001:4860:4802::1", ""},
+		{"157.55.39.200", "ip_range:Bing Preview"},
+		{"198.51.100.7", "ip_range:198.51.100.7"},
+		{"198.51.100.8", ""},
+		{"not-an-ip", ""},
+	} {
+		got := d.Classify(tc.ip, browserAgent)
+		want := Verdict{IsBot: tc.reason != "", Reason: tc.reason}
+		if got != want {
+			t.Errorf("Classify(%q) = %+v, want %+v", tc.ip, got, want)
+		}
+	}
+
+	// A broken file keeps the previous ranges
+	writeRanges(t, path, "66.249.64.0/19 Googlebot\nnot-a-range\n")
+	if err := d.Reload(); err == nil {
+		t.Error("Reload of an invalid file succeeded")
+	}
+	if !d.Classify("157.55.39.200", browserAgent).IsBot {
+		t.Error("ranges changed by a failed reload")
+	}
+
+	writeRanges(t, path, "203.0.113.0/24 Example\n")
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Classify("66.249.66.1", browserAgent).IsBot || !d.Classify("203.0.113.9", browserAgent).IsBot {
+		t.Error("reloaded ranges not in effect")
+	}
+
+	if _, err := New(Config{RangesFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("New with a missing ranges file succeeded")
+	}
+}
+
+func TestClassifyRate(t *testing.T) {
+	d, err := New(Config{RateLimit: 3, RateWindow: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if v := d.Classify("203.0.113.10", browserAgent); v.IsBot {
+			t.Fatalf("hit %d = %+v, want human", i, v)
+		}
+	}
+	if v := d.Classify("203.0.113.10", browserAgent); v != (Verdict{IsBot: true, Reason: ReasonRate}) {
+		t.Errorf("hit over the limit = %+v, want rate", v)
+	}
+
+	// Counted per IP, with mapped and plain forms the same IP
+	if v := d.Classify("203.0.113.11", browserAgent); v.IsBot {
+		t.Errorf("other IP = %+v, want human", v)
+	}
+	if v := d.Classify("::ffff:203.0.113.10", browserAgent); v.Reason != ReasonRate {
+		t.Errorf("mapped IP = %+v, want rate", v)
+	}
+
+	// Hits that match otherwise still count
+	d, err = New(Config{RateLimit: 1, RateWindow: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Classify("203.0.113.12", "curl/8.4.0")
+	if v := d.Classify("203.0.113.12", browserAgent); v.Reason != ReasonRate {
+		t.Errorf("after a curl hit = %+v, want rate", v)
+	}
+}
+
+func TestRateCounter(t *testing.T) {
+	c := newRateCounter(time.Second)
+	a := netip.MustParseAddr("203.0.113.1")
+	b := netip.MustParseAddr("203.0.113.2")
+	start := time.Now()
+
+	for i, tc := range []struct {
+		addr  netip.Addr
+		after time.Duration
+		want  int
+	}{
+		{a, 0, 1},
+		{a, 500 * time.Millisecond, 2},
+		{b, 600 * time.Millisecond, 1},
+		{a, 999 * time.Millisecond, 3},
+		{a, time.Second, 1}, // new window
+		{b, 1100 * time.Millisecond, 2},
+		{b, 1600 * time.Millisecond, 1},
+	} {
+		if got := c.hit(tc.addr, start.Add(tc.after)); got != tc.want {
+			t.Errorf("hit %d (%s at +%s) = %d, want %d", i, tc.addr, tc.after, got, tc.want)
+		}
+	}
+
+	// Idle IPs are forgotten on the next sweep
+	c.hit(a, start.Add(time.Minute))
+	if _, ok := c.counts[b]; ok || len(c.counts) != 1 {
+		t.Errorf("counts after sweep = %v, want only %s", c.counts, a)
+	}
+}
@@ -0,0 +1,119 @@
+// Package geoip resolves IP addresses to locations using a local
+// MaxMind-format (.mmdb) database such as GeoLite2-City or GeoLite2-Country.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/reloader"
+)
+
+type Config struct {
+	// File is the path of the .mmdb database
+	File string
+	// RefreshInterval is how often the file is checked for changes
+	RefreshInterval time.Duration
+}
+
+// Location is where an IP address is registered. Fields the database has
+// no data for are empty; Country and Region are ISO 3166 codes.
+type Location struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// record holds the fields of the GeoIP2/GeoLite2 City and Country schemas
+// that Location uses
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// DB is a GeoIP database held in memory. Reloads open the new file
+// completely before swapping it in, so lookups never see a partial one.
+type DB struct {
+	config Config
+	reader atomic.Pointer[maxminddb.Reader]
+}
+
+func New(config Config) (*DB, error) {
+	db := &DB{config: config}
+	if err := db.Reload(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Reload re-reads the database file. On error the previous database stays
+// in use.
+func (db *DB) Reload() error {
+	// Read into memory rather than mmap so a replaced reader can be left to
+	// the garbage collector while lookups may still use it
+	data, err := os.ReadFile(db.config.File)
+	if err != nil {
+		return fmt.Errorf("failed to read GeoIP database %s: %w", db.config.File, err)
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to open GeoIP database %s: %w", db.config.File, err)
+	}
+
+	db.reader.Store(reader)
+	return nil
+}
+
+// Watch reloads the database when its file changes or on SIGHUP until ctx
+// is cancelled. Reload errors are passed to onError.
+func (db *DB) Watch(ctx context.Context, onError func(error)) {
+	reloader.Watch(ctx, db.config.RefreshInterval, []string{db.config.File}, func() {
+		if err := db.Reload(); err != nil {
+			onError(err)
+		}
+	})
+}
+
+// Lookup returns the location of ip and whether the database has an entry
+// for it
+func (db *DB) Lookup(ip net.IP) (Location, bool, error) {
+	var rec record
+	_, ok, err := db.reader.Load().LookupNetwork(ip, &rec)
+	if err != nil || !ok {
+		return Location{}, false, err
+	}
+
+	location := Location{
+		Country: rec.Country.ISOCode,
+		City:    rec.City.Names["en"],
+	}
+	if len(rec.Subdivisions) > 0 {
+		location.Region = rec.Subdivisions[0].ISOCode
+	}
+
+	return location, true, nil
+}
+
+// LookupString is Lookup for an address in string form. Invalid addresses
+// have no entry.
+func (db *DB) LookupString(ip string) (Location, bool, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, false, nil
+	}
+	return db.Lookup(parsed)
+}
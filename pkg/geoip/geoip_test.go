@@ -0,0 +1,227 @@
+package geoip
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The databases in testdata are written by testdata/mkmmdb.py
+const (
+	testDB   = "testdata/test-city.mmdb"
+	testDBv2 = "testdata/test-city-v2.mmdb"
+)
+
+func TestLookup(t *testing.T) {
+	db, err := New(Config{File: testDB})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		ip   string
+		want Location
+		ok   bool
+	}{
+		{"city", "81.2.69.142", Location{Country: "GB", Region: "ENG", City: "London"}, true},
+		{"other city", "216.160.83.56", Location{Country: "US", Region: "WA", City: "Milton"}, true},
+		{"country only", "2.125.160.216", Location{Country: "GB"}, true},
+		{"last address of network", "81.2.69.255", Location{Country: "GB", Region: "ENG", City: "London"}, true},
+		{"unknown", "8.8.8.8", Location{}, false},
+		{"next to a network", "81.2.70.1", Location{}, false},
+		{"RFC1918", "10.1.2.3", Location{}, false},
+		{"RFC1918 192.168", "192.168.0.10", Location{}, false},
+		{"loopback", "127.0.0.1", Location{}, false},
+		{"IPv4-mapped", "::ffff:81.2.69.142", Location{Country: "GB", Region: "ENG", City: "London"}, true},
+		{"invalid", "not-an-ip", Location{}, false},
+		{"empty", "", Location{}, false},
+		{"with port", "81.2.69.142:443", Location{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := db.LookupString(tc.ip)
+			if err != nil {
+				t.Fatalf("LookupString(%q): %v", tc.ip, err)
+			}
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("LookupString(%q) = %+v, %v; want %+v, %v", tc.ip, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestLookupIPv6InIPv4Database(t *testing.T) {
+	db, err := New(Config{File: testDB})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := db.Lookup(net.ParseIP("2001:db8::1")); err == nil || ok {
+		t.Errorf("Lookup(2001:db8::1) = %v, %v; want an error from an IPv4-only database", ok, err)
+	}
+}
+
+func TestNewRejectsBadFiles(t *testing.T) {
+	if _, err := New(Config{File: filepath.Join(t.TempDir(), "missing.mmdb")}); err == nil {
+		t.Error("New with a missing file succeeded")
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.mmdb")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Config{File: garbage}); err == nil {
+		t.Error("New with a corrupt file succeeded")
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	copyFile(t, testDB, path)
+
+	db, err := New(Config{File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCity(t, db, "London")
+
+	copyFile(t, testDBv2, path)
+	if err := db.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	assertCity(t, db, "Greenwich")
+
+	// A broken file keeps the last good database in use
+	if err := os.WriteFile(path, []byte("truncated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Reload(); err == nil {
+		t.Error("Reload of a corrupt file succeeded")
+	}
+	assertCity(t, db, "Greenwich")
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Reload(); err == nil {
+		t.Error("Reload of a missing file succeeded")
+	}
+	assertCity(t, db, "Greenwich")
+}
+
+// Lookups racing with reloads must always see one whole database
+func TestReloadConcurrentLookups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "city.mmdb")
+	copyFile(t, testDB, path)
+
+	db, err := New(Config{File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				location, ok, err := db.LookupString("81.2.69.142")
+				if err != nil || !ok || (location.City != "London" && location.City != "Greenwich") {
+					t.Errorf("LookupString during reload = %+v, %v, %v", location, ok, err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		source := testDB
+		if i%2 == 0 {
+			source = testDBv2
+		}
+		// Replace by rename, as database updaters do
+		next := filepath.Join(dir, "next.mmdb")
+		copyFile(t, source, next)
+		if err := os.Rename(next, path); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	copyFile(t, testDB, path)
+
+	db, err := New(Config{File: path, RefreshInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.Watch(ctx, func(err error) { t.Errorf("Watch: %v", err) })
+	}()
+	// Stop the watcher before the temporary directory goes away
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	copyFile(t, testDBv2, path)
+
+	// The watcher may take its first look at the file after the copy, so
+	// keep moving the modification time until it notices
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 1; ; i++ {
+		modTime := time.Now().Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+
+		location, _, _ := db.LookupString("81.2.69.142")
+		if location.City == "Greenwich" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("database not reloaded, city still %q", location.City)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func assertCity(t *testing.T, db *DB, want string) {
+	t.Helper()
+
+	location, ok, err := db.LookupString("81.2.69.142")
+	if err != nil || !ok || location.City != want {
+		t.Errorf("LookupString = %+v, %v, %v; want city %q", location, ok, err, want)
+	}
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
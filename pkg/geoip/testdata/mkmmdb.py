@@ -0,0 +1,113 @@
+#!/usr/bin/env python3
+"""Writes the tiny IPv4 GeoIP2-City style databases used by the geoip tests.
+
+    python3 mkmmdb.py
+
+test-city.mmdb and test-city-v2.mmdb differ only in the city of
+81.2.69.0/24, so tests can tell which one a reload left in use.
+"""
+import ipaddress
+
+
+def ctrl(t, size):
+    assert size < 29
+    if t <= 7:
+        return bytes([(t << 5) | size])
+    return bytes([size, t - 7])
+
+
+def string(x):
+    b = x.encode()
+    return ctrl(2, len(b)) + b
+
+
+def uint(t, v, width):
+    b = v.to_bytes(width, "big").lstrip(b"\0")
+    return ctrl(t, len(b)) + b
+
+
+def mapping(d):
+    out = ctrl(7, len(d))
+    for k, v in d.items():
+        out += string(k) + v
+    return out
+
+
+def array(items):
+    return ctrl(11, len(items)) + b"".join(items)
+
+
+def city_record(country, region=None, city=None):
+    fields = {"country": mapping({"iso_code": string(country)})}
+    if region:
+        fields["subdivisions"] = array([mapping({"iso_code": string(region)})])
+    if city:
+        fields["city"] = mapping({"names": mapping({"en": string(city)})})
+    return mapping(fields)
+
+
+def build(networks):
+    # Binary trie over the prefix bits; leaves hold a data section offset
+    root = {}
+    data = b""
+    for cidr, record in networks:
+        net = ipaddress.ip_network(cidr)
+        bits = format(int(net.network_address), "032b")[: net.prefixlen]
+        node = root
+        for bit in bits[:-1]:
+            node = node.setdefault(bit, {})
+        node[bits[-1]] = len(data)
+        data += record
+
+    nodes = []
+
+    def number(node):
+        index = len(nodes)
+        nodes.append(None)
+        nodes[index] = [child(node.get("0")), child(node.get("1"))]
+        return index
+
+    def child(node):
+        if node is None:
+            return None
+        if isinstance(node, int):
+            return ("data", node)
+        return ("node", number(node))
+
+    number(root)
+    node_count = len(nodes)
+
+    def record(r):
+        if r is None:
+            return node_count
+        kind, value = r
+        return value if kind == "node" else node_count + 16 + value
+
+    tree = b"".join(record(l).to_bytes(3, "big") + record(r).to_bytes(3, "big") for l, r in nodes)
+    metadata = mapping({
+        "node_count": uint(6, node_count, 4),
+        "record_size": uint(5, 24, 2),
+        "ip_version": uint(5, 4, 2),
+        "database_type": string("Test-City"),
+        "languages": array([string("en")]),
+        "binary_format_major_version": uint(5, 2, 2),
+        "binary_format_minor_version": uint(5, 0, 2),
+        "build_epoch": uint(9, 1700000000, 8),
+        "description": mapping({"en": string("geoip test database")}),
+    })
+    return tree + b"\0" * 16 + data + b"\xab\xcd\xefMaxMind.com" + metadata
+
+
+def networks(london):
+    return [
+        ("81.2.69.0/24", city_record("GB", "ENG", london)),
+        ("216.160.83.0/24", city_record("US", "WA", "Milton")),
+        ("2.125.160.0/20", city_record("GB")),
+    ]
+
+
+if __name__ == "__main__":
+    with open("test-city.mmdb", "wb") as f:
+        f.write(build(networks("London")))
+    with open("test-city-v2.mmdb", "wb") as f:
+        f.write(build(networks("Greenwich")))
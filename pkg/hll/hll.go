@@ -0,0 +1,108 @@
+// Package hll implements HyperLogLog sketches for estimating the number of
+// distinct values, such as unique visitors, in a fixed amount of memory.
+package hll
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// Precision is the number of hash bits used to pick a register. 2^11
+// registers give a standard error of about 2.3% in 2 KiB.
+const Precision = 11
+
+const registers = 1 << Precision
+
+var ErrInvalidSketch = errors.New("invalid HyperLogLog sketch")
+
+// Sketch is a HyperLogLog sketch. The zero value is not usable; create
+// sketches with New or Unmarshal.
+type Sketch struct {
+	registers []uint8
+}
+
+func New() *Sketch {
+	return &Sketch{registers: make([]uint8, registers)}
+}
+
+// Unmarshal restores a sketch serialized by Bytes. An empty slice yields
+// an empty sketch.
+func Unmarshal(data []byte) (*Sketch, error) {
+	if len(data) == 0 {
+		return New(), nil
+	}
+	if len(data) != registers {
+		return nil, ErrInvalidSketch
+	}
+
+	s := New()
+	copy(s.registers, data)
+	return s, nil
+}
+
+// Bytes serializes the sketch
+func (s *Sketch) Bytes() []byte {
+	out := make([]byte, len(s.registers))
+	copy(out, s.registers)
+	return out
+}
+
+// AddString adds a value to the sketch
+func (s *Sketch) AddString(value string) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	s.AddHash(mix(h.Sum64()))
+}
+
+// AddHash adds a value by its uniformly distributed 64-bit hash
+func (s *Sketch) AddHash(hash uint64) {
+	index := hash >> (64 - Precision)
+	rank := uint8(bits.LeadingZeros64(hash<<Precision|1<<(Precision-1)) + 1)
+	if rank > s.registers[index] {
+		s.registers[index] = rank
+	}
+}
+
+// Merge folds other into s, so s estimates the union of both
+func (s *Sketch) Merge(other *Sketch) {
+	for i, rank := range other.registers {
+		if rank > s.registers[i] {
+			s.registers[i] = rank
+		}
+	}
+}
+
+// Estimate returns the approximate number of distinct values added
+func (s *Sketch) Estimate() uint64 {
+	m := float64(registers)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	for _, rank := range s.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// mix is the splitmix64 finalizer, spreading FNV's weak high bits
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,72 @@
+package reloader
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch calls reload whenever one of the files changes or the process
+// receives SIGHUP, until ctx is cancelled. Files are polled every interval;
+// a zero interval disables polling so only SIGHUP triggers a reload.
+func Watch(ctx context.Context, interval time.Duration, paths []string, reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	states := statFiles(paths)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			states = statFiles(paths)
+			reload()
+		case <-tick:
+			current := statFiles(paths)
+			if changed(states, current) {
+				states = current
+				reload()
+			}
+		}
+	}
+}
+
+func statFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // a missing file counts as changed once it reappears
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states
+}
+
+func changed(before, after map[string]fileState) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for path, state := range after {
+		if before[path] != state {
+			return true
+		}
+	}
+	return false
+}
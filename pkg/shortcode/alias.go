@@ -0,0 +1,48 @@
+package shortcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MinAliasLength = 3
+	MaxAliasLength = 32
+)
+
+// ReservedAliases are path segments served by the HTTP router itself. A
+// custom alias must never shadow one of them.
+var ReservedAliases = map[string]struct{}{
+	"admin":   {},
+	"api":     {},
+	"health":  {},
+	"metrics": {},
+}
+
+// ValidateAlias checks that a user supplied short code only uses URL-safe
+// characters, has a sensible length and does not collide with a reserved
+// route name.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return fmt.Errorf("alias must be between %d and %d characters",
+			MinAliasLength, MaxAliasLength)
+	}
+
+	for i := 0; i < len(alias); i++ {
+		c := alias[i]
+		isAlnum := (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+		if !isAlnum && c != '-' && c != '_' {
+			return fmt.Errorf("alias may only contain letters, digits, '-' and '_'")
+		}
+	}
+
+	if alias[0] == '-' || alias[0] == '_' || alias[len(alias)-1] == '-' || alias[len(alias)-1] == '_' {
+		return fmt.Errorf("alias must start and end with a letter or digit")
+	}
+
+	if _, reserved := ReservedAliases[strings.ToLower(alias)]; reserved {
+		return fmt.Errorf("alias %q is reserved", alias)
+	}
+
+	return nil
+}
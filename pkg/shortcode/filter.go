@@ -0,0 +1,187 @@
+package shortcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CodeChecker is implemented by generators that can vet short codes they
+// did not generate themselves, such as codes derived from row IDs.
+type CodeChecker interface {
+	Check(code string) error
+}
+
+// AliasChecker is implemented by generators that can vet custom aliases.
+// Aliases are made of words, so they get a word-based check rather than the
+// substring check applied to random codes.
+type AliasChecker interface {
+	CheckAlias(alias string) error
+}
+
+var ErrBlockedCode = errors.New("short code contains a blocked word")
+
+// leetspeak maps look-alike digits and symbols to the letters they are
+// commonly used for. '1' is handled separately as it stands for both i and l.
+var leetspeak = strings.NewReplacer(
+	"0", "o", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g",
+	"@", "a", "$", "s", "!", "i", "|", "l", "+", "t",
+)
+
+// Denylist holds blocked words, matched case-insensitively and through
+// common leetspeak substitutions. Generated codes are matched by substring,
+// custom aliases by whole word.
+type Denylist struct {
+	words []string
+}
+
+func NewDenylist(words []string) *Denylist {
+	d := &Denylist{}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			d.words = append(d.words, word)
+		}
+	}
+	return d
+}
+
+// LoadDenylist reads one word per line. Blank lines and lines starting
+// with '#' are ignored.
+func LoadDenylist(path string) (*Denylist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open denylist: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+
+	return NewDenylist(words), nil
+}
+
+// Match returns the first blocked word found anywhere in code
+func (d *Denylist) Match(code string) (string, bool) {
+	for _, variant := range normalizedVariants(code) {
+		for _, word := range d.words {
+			if strings.Contains(variant, word) {
+				return word, true
+			}
+		}
+	}
+	return "", false
+}
+
+// MatchAlias returns the first blocked word making up a whole '-' or '_'
+// separated word of alias. Substrings are not matched, so aliases such as
+// "analytics" or "grape-sale" stay available, while spelling a word out
+// with separators ("f-o-o") still matches.
+func (d *Denylist) MatchAlias(alias string) (string, bool) {
+	tokens := strings.FieldsFunc(alias, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(tokens) > 1 {
+		tokens = append(tokens, strings.Join(tokens, ""))
+	}
+
+	for _, token := range tokens {
+		for _, variant := range normalizedVariants(token) {
+			for _, word := range d.words {
+				if variant == word {
+					return word, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func normalizedVariants(code string) []string {
+	lower := strings.ToLower(code)
+	leet := leetspeak.Replace(lower)
+	return []string{
+		lower,
+		strings.ReplaceAll(leet, "1", "i"),
+		strings.ReplaceAll(leet, "1", "l"),
+	}
+}
+
+// FilteredGenerator wraps a Generator and regenerates any code that
+// contains a blocked word.
+type FilteredGenerator struct {
+	generator   Generator
+	denylist    *Denylist
+	maxAttempts int
+}
+
+func NewFilteredGenerator(generator Generator, denylist *Denylist) *FilteredGenerator {
+	return &FilteredGenerator{
+		generator:   generator,
+		denylist:    denylist,
+		maxAttempts: 10,
+	}
+}
+
+func (g *FilteredGenerator) Generate() (string, error) {
+	return g.generate(g.generator.Generate)
+}
+
+func (g *FilteredGenerator) GenerateWithLength(length int) (string, error) {
+	return g.generate(func() (string, error) {
+		return g.generator.GenerateWithLength(length)
+	})
+}
+
+func (g *FilteredGenerator) generate(next func() (string, error)) (string, error) {
+	for i := 0; i < g.maxAttempts; i++ {
+		code, err := next()
+		if err != nil {
+			return "", err
+		}
+		if _, blocked := g.denylist.Match(code); !blocked {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no clean code after %d attempts", ErrBlockedCode, g.maxAttempts)
+}
+
+// Check rejects codes containing a blocked word
+func (g *FilteredGenerator) Check(code string) error {
+	if word, blocked := g.denylist.Match(code); blocked {
+		return fmt.Errorf("%w: %q", ErrBlockedCode, word)
+	}
+	return nil
+}
+
+// CheckAlias rejects aliases with a blocked word among their words
+func (g *FilteredGenerator) CheckAlias(alias string) error {
+	if word, blocked := g.denylist.MatchAlias(alias); blocked {
+		return fmt.Errorf("%w: %q", ErrBlockedCode, word)
+	}
+	return nil
+}
+
+// Unique reports whether the wrapped generator is collision-free
+func (g *FilteredGenerator) Unique() bool {
+	unique, ok := g.generator.(UniqueGenerator)
+	return ok && unique.Unique()
+}
+
+// Unwrap returns the wrapped generator
+func (g *FilteredGenerator) Unwrap() Generator {
+	return g.generator
+}
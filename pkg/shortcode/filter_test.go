@@ -0,0 +1,155 @@
+package shortcode
+
+import (
+	"errors"
+	"testing"
+)
+
+func loadShippedDenylist(t *testing.T) *Denylist {
+	t.Helper()
+
+	denylist, err := LoadDenylist("../../configs/shortcode_denylist.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return denylist
+}
+
+func TestDenylistMatchCode(t *testing.T) {
+	denylist := loadShippedDenylist(t)
+
+	for _, tc := range []struct {
+		code string
+		want string // "" when the code is clean
+	}{
+		{"xSHiTq", "shit"},
+		{"ab5h1tZ", "shit"}, // 5 -> s, 1 -> i
+		{"q8itch", "bitch"}, // 8 -> b
+		{"p0rn42", "porn"},  // 0 -> o
+		{"4n4l", "anal"},    // 4 -> a
+		{"Tw4T9x", "twat"},  // case and 4 -> a
+		{"nudeS3x", "sex"},  // 3 -> e
+		{"Kk7xYz", ""},      // 7 -> t, no word
+		{"aB3dEf", ""},
+		{"h3ll0W", ""},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			word, blocked := denylist.Match(tc.code)
+			if tc.want == "" {
+				if blocked {
+					t.Errorf("Match(%q) = %q, want no match", tc.code, word)
+				}
+				return
+			}
+			if !blocked || word != tc.want {
+				t.Errorf("Match(%q) = %q, %v; want %q", tc.code, word, blocked, tc.want)
+			}
+		})
+	}
+}
+
+func TestDenylistMatchAlias(t *testing.T) {
+	denylist := loadShippedDenylist(t)
+
+	for _, tc := range []struct {
+		alias string
+		want  string // "" when the alias is allowed
+	}{
+		// Words that merely contain a blocked word
+		{"analytics", ""},
+		{"grape-sale", ""},
+		{"sussex", ""},
+		{"cockpit", ""},
+		{"scrape", ""},
+		{"therapist", ""},
+		{"essex_news", ""},
+		{"spring-sale-2026", ""},
+
+		// Blocked words as whole words
+		{"sex", "sex"},
+		{"free-porn", "porn"},
+		{"my_Sh1t_link", "shit"},
+		{"p0rn-hub", "porn"},
+		{"big-4n4l", "anal"},
+
+		// Spelt out with separators
+		{"f-u-c-k", "fuck"},
+		{"s_3_x", "sex"},
+	} {
+		t.Run(tc.alias, func(t *testing.T) {
+			word, blocked := denylist.MatchAlias(tc.alias)
+			if tc.want == "" {
+				if blocked {
+					t.Errorf("MatchAlias(%q) = %q, want no match", tc.alias, word)
+				}
+				return
+			}
+			if !blocked || word != tc.want {
+				t.Errorf("MatchAlias(%q) = %q, %v; want %q", tc.alias, word, blocked, tc.want)
+			}
+		})
+	}
+}
+
+// sequenceGenerator returns codes in order, then fails
+type sequenceGenerator struct {
+	codes []string
+	calls int
+}
+
+func (g *sequenceGenerator) Generate() (string, error) {
+	if g.calls >= len(g.codes) {
+		return "", errors.New("out of codes")
+	}
+	code := g.codes[g.calls]
+	g.calls++
+	return code, nil
+}
+
+func (g *sequenceGenerator) GenerateWithLength(length int) (string, error) {
+	return g.Generate()
+}
+
+func TestFilteredGeneratorRegenerates(t *testing.T) {
+	inner := &sequenceGenerator{codes: []string{"aSEXb", "p0rnq", "clean1"}}
+	g := NewFilteredGenerator(inner, NewDenylist([]string{"sex", "porn"}))
+
+	code, err := g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "clean1" || inner.calls != 3 {
+		t.Errorf("Generate() = %q after %d codes, want clean1 after 3", code, inner.calls)
+	}
+}
+
+func TestFilteredGeneratorGivesUp(t *testing.T) {
+	codes := make([]string, 20)
+	for i := range codes {
+		codes[i] = "xsexx"
+	}
+	inner := &sequenceGenerator{codes: codes}
+	g := NewFilteredGenerator(inner, NewDenylist([]string{"sex"}))
+
+	if code, err := g.GenerateWithLength(5); !errors.Is(err, ErrBlockedCode) {
+		t.Errorf("GenerateWithLength() = %q, %v; want %v", code, err, ErrBlockedCode)
+	}
+	if inner.calls != g.maxAttempts {
+		t.Errorf("generated %d codes, want %d attempts", inner.calls, g.maxAttempts)
+	}
+}
+
+func TestFilteredGeneratorChecks(t *testing.T) {
+	g := NewFilteredGenerator(NewBase62Generator(), loadShippedDenylist(t))
+
+	// Codes are checked by substring, aliases by whole word
+	if err := g.Check("Analytics"); !errors.Is(err, ErrBlockedCode) {
+		t.Errorf("Check(Analytics) = %v, want %v", err, ErrBlockedCode)
+	}
+	if err := g.CheckAlias("Analytics"); err != nil {
+		t.Errorf("CheckAlias(Analytics) = %v, want nil", err)
+	}
+	if err := g.CheckAlias("free-p0rn"); !errors.Is(err, ErrBlockedCode) {
+		t.Errorf("CheckAlias(free-p0rn) = %v, want %v", err, ErrBlockedCode)
+	}
+}
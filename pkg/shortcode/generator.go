@@ -11,6 +11,24 @@ type Generator interface {
 	GenerateWithLength(length int) (string, error)
 }
 
+// UniqueGenerator is implemented by generators whose codes never collide
+// with each other, so callers can skip the uniqueness lookup.
+type UniqueGenerator interface {
+	Generator
+	Unique() bool
+}
+
+// Unwrap returns the innermost generator of a chain of wrapping generators
+func Unwrap(g Generator) Generator {
+	for {
+		wrapper, ok := g.(interface{ Unwrap() Generator })
+		if !ok {
+			return g
+		}
+		g = wrapper.Unwrap()
+	}
+}
+
 type Base62Generator struct {
 	length  int
 	charset string
@@ -20,6 +38,9 @@ const (
 	// Base62 charset (0-9, A-Z, a-z)
 	DefaultCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	DefaultLength  = 8 // Increased from 6 to 8 for more unique combinations
+
+	// Base62 without look-alikes (0/O, 1/l/I) that users mistype from print
+	UnambiguousCharset = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 )
 
 func NewBase62Generator() *Base62Generator {
@@ -43,6 +64,14 @@ func NewBase62GeneratorWithLength(length int) *Base62Generator {
 	}
 }
 
+func NewBase62GeneratorWithCharset(length int, charset string) *Base62Generator {
+	g := NewBase62GeneratorWithLength(length)
+	if charset != "" {
+		g.charset = charset
+	}
+	return g
+}
+
 func (g *Base62Generator) Generate() (string, error) {
 	return g.GenerateWithLength(g.length)
 }
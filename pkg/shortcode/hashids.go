@@ -0,0 +1,74 @@
+package shortcode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// IDEncoder derives short codes from numeric row IDs and back. Codes
+// derived this way are unique by construction.
+type IDEncoder interface {
+	Encode(id int64) (string, error)
+	Decode(code string) (int64, error)
+}
+
+var ErrRequiresID = errors.New("short code must be derived from a row ID")
+
+// HashidsGenerator obfuscates sequential IDs using a secret salt and a
+// salt-shuffled base62 alphabet, so codes are short but not guessable.
+type HashidsGenerator struct {
+	hashID *hashids.HashID
+}
+
+func NewHashidsGenerator(salt string, minLength int) (*HashidsGenerator, error) {
+	if salt == "" {
+		return nil, fmt.Errorf("hashids generator requires a salt")
+	}
+
+	data := hashids.NewData()
+	data.Alphabet = DefaultCharset
+	data.Salt = salt
+	data.MinLength = minLength
+
+	hashID, err := hashids.NewWithData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hashids: %w", err)
+	}
+
+	return &HashidsGenerator{hashID: hashID}, nil
+}
+
+func (g *HashidsGenerator) Encode(id int64) (string, error) {
+	if id < 0 {
+		return "", fmt.Errorf("cannot encode negative ID %d", id)
+	}
+	return g.hashID.EncodeInt64([]int64{id})
+}
+
+func (g *HashidsGenerator) Decode(code string) (int64, error) {
+	if code == "" {
+		return 0, fmt.Errorf("cannot decode empty short code")
+	}
+
+	ids, err := g.hashID.DecodeInt64WithError(code)
+	if err != nil {
+		return 0, fmt.Errorf("invalid short code %q: %w", code, err)
+	}
+	if len(ids) != 1 {
+		return 0, fmt.Errorf("invalid short code %q: expected one ID, got %d", code, len(ids))
+	}
+
+	return ids[0], nil
+}
+
+// Generate always fails: hashids codes can only be produced once the row ID
+// is known, via Encode.
+func (g *HashidsGenerator) Generate() (string, error) {
+	return "", ErrRequiresID
+}
+
+func (g *HashidsGenerator) GenerateWithLength(length int) (string, error) {
+	return "", ErrRequiresID
+}
@@ -0,0 +1,163 @@
+package shortcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func TestHashidsRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		salt      string
+		minLength int
+	}{
+		{"no min length", "salt-a", 0},
+		{"min length 6", "salt-a", 6},
+		{"min length 10", "salt-a", 10},
+		{"other salt", "another secret salt", 6},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewHashidsGenerator(tc.salt, tc.minLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []int64{0, 1, 2, 61, 62, 1 << 31, 1<<62 + 12345}
+			for id := int64(100); id < 2100; id++ {
+				ids = append(ids, id)
+			}
+
+			seen := make(map[string]int64)
+			for _, id := range ids {
+				code, err := g.Encode(id)
+				if err != nil {
+					t.Fatalf("Encode(%d): %v", id, err)
+				}
+				if len(code) < tc.minLength {
+					t.Errorf("Encode(%d) = %q, shorter than %d", id, code, tc.minLength)
+				}
+				if strings.Trim(code, DefaultCharset) != "" {
+					t.Errorf("Encode(%d) = %q, outside the base62 alphabet", id, code)
+				}
+				if other, ok := seen[code]; ok {
+					t.Fatalf("Encode(%d) = %q, same as for %d", id, code, other)
+				}
+				seen[code] = id
+
+				decoded, err := g.Decode(code)
+				if err != nil {
+					t.Fatalf("Decode(%q): %v", code, err)
+				}
+				if decoded != id {
+					t.Errorf("Decode(Encode(%d)) = %d", id, decoded)
+				}
+			}
+		})
+	}
+}
+
+func TestHashidsSaltChangesCodes(t *testing.T) {
+	a, err := NewHashidsGenerator("salt-a", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewHashidsGenerator("salt-b", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same := 0
+	for id := int64(1); id <= 100; id++ {
+		codeA, _ := a.Encode(id)
+		codeB, _ := b.Encode(id)
+		if codeA == codeB {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Errorf("%d of 100 ids encode the same under different salts", same)
+	}
+}
+
+func TestHashidsRejectsForeignCodes(t *testing.T) {
+	g, err := NewHashidsGenerator("salt-a", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewHashidsGenerator("salt-b", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hashids carry no checksum, so a code of another salt or generator can
+	// happen to be valid. Most must fail, and those that decode must be the
+	// one canonical code of their ID, never an alias of another code.
+	var foreign []string
+	for id := int64(1); id <= 200; id++ {
+		code, _ := other.Encode(id)
+		foreign = append(foreign, code)
+	}
+	base62 := NewBase62GeneratorWithLength(10)
+	for i := 0; i < 200; i++ {
+		code, err := base62.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		foreign = append(foreign, code)
+	}
+
+	accepted := 0
+	for _, code := range foreign {
+		decoded, err := g.Decode(code)
+		if err != nil {
+			continue
+		}
+		accepted++
+		if canonical, _ := g.Encode(decoded); canonical != code {
+			t.Errorf("Decode(%q) = %d, which encodes as %q", code, decoded, canonical)
+		}
+	}
+	if accepted > len(foreign)/10 {
+		t.Errorf("%d of %d foreign codes decoded", accepted, len(foreign))
+	}
+
+	// A valid hashid of several numbers is not a row ID
+	data := hashids.NewData()
+	data.Alphabet = DefaultCharset
+	data.Salt = "salt-a"
+	data.MinLength = 6
+	multi, err := hashids.NewWithData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := multi.EncodeInt64([]int64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, code := range []string{"", "abc-def", "héllo", "my-alias", pair} {
+		if decoded, err := g.Decode(code); err == nil {
+			t.Errorf("Decode(%q) = %d, want error", code, decoded)
+		}
+	}
+}
+
+func TestHashidsGenerator(t *testing.T) {
+	if _, err := NewHashidsGenerator("", 6); err == nil {
+		t.Error("NewHashidsGenerator without salt succeeded")
+	}
+
+	g, err := NewHashidsGenerator("salt-a", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Encode(-1); err == nil {
+		t.Error("Encode(-1) succeeded")
+	}
+	if _, err := g.Generate(); !errors.Is(err, ErrRequiresID) {
+		t.Errorf("Generate() error = %v, want ErrRequiresID", err)
+	}
+}
@@ -0,0 +1,165 @@
+package shortcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snowflake IDs pack 41 bits of milliseconds since SnowflakeEpoch, 10 bits
+// of machine ID and a 12 bit per-millisecond sequence into a 63 bit integer.
+const (
+	SnowflakeEpoch = int64(1704067200000) // 2024-01-01T00:00:00Z in milliseconds
+
+	machineIDBits = 10
+	sequenceBits  = 12
+
+	MaxMachineID = int64(1)<<machineIDBits - 1
+	maxSequence  = int64(1)<<sequenceBits - 1
+
+	// SnowflakeCodeLength is the width of an encoded ID. Codes are left
+	// padded to it so that they sort chronologically as plain strings.
+	SnowflakeCodeLength = 11
+
+	// minSnowflakeCharset is the smallest charset that fits a 63 bit ID in
+	// SnowflakeCodeLength characters (53^11 > 2^63)
+	minSnowflakeCharset = 53
+
+	// maxClockRollback is how far the clock may step back (e.g. an NTP
+	// correction) before Generate gives up instead of waiting it out.
+	maxClockRollback = 50 * time.Millisecond
+)
+
+var ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+// SnowflakeGenerator produces time-ordered codes that are unique across
+// instances as long as every instance uses a distinct machine ID.
+type SnowflakeGenerator struct {
+	mu            sync.Mutex
+	machineID     int64
+	lastTimestamp int64
+	sequence      int64
+	charset       string
+	now           func() time.Time
+}
+
+func NewSnowflakeGenerator(machineID int64) (*SnowflakeGenerator, error) {
+	return NewSnowflakeGeneratorWithCharset(machineID, DefaultCharset)
+}
+
+// NewSnowflakeGeneratorWithCharset encodes IDs with charset instead of
+// DefaultCharset. Its characters must be in ascending byte order, so codes
+// still sort chronologically, and there must be at least 53 of them.
+func NewSnowflakeGeneratorWithCharset(machineID int64, charset string) (*SnowflakeGenerator, error) {
+	if machineID < 0 || machineID > MaxMachineID {
+		return nil, fmt.Errorf("machine ID must be between 0 and %d, got %d",
+			MaxMachineID, machineID)
+	}
+	if len(charset) < minSnowflakeCharset {
+		return nil, fmt.Errorf("snowflake charset needs at least %d characters, got %d",
+			minSnowflakeCharset, len(charset))
+	}
+	for i := 1; i < len(charset); i++ {
+		if charset[i-1] >= charset[i] {
+			return nil, fmt.Errorf("snowflake charset must be in ascending order without repeats")
+		}
+	}
+
+	return &SnowflakeGenerator{
+		machineID: machineID,
+		charset:   charset,
+		now:       time.Now,
+	}, nil
+}
+
+func (g *SnowflakeGenerator) Generate() (string, error) {
+	return g.GenerateWithLength(SnowflakeCodeLength)
+}
+
+// GenerateWithLength pads the code to length. Lengths below
+// SnowflakeCodeLength are ignored since truncating would break uniqueness.
+func (g *SnowflakeGenerator) GenerateWithLength(length int) (string, error) {
+	id, err := g.NextID()
+	if err != nil {
+		return "", err
+	}
+
+	if length < SnowflakeCodeLength {
+		length = SnowflakeCodeLength
+	}
+
+	return encodeID(uint64(id), g.charset, length), nil
+}
+
+// Unique reports that snowflake codes never collide with each other
+func (g *SnowflakeGenerator) Unique() bool {
+	return true
+}
+
+// NextID returns the next raw snowflake ID
+func (g *SnowflakeGenerator) NextID() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ts := g.currentMillis()
+
+	// Never hand out a timestamp older than one already used, otherwise the
+	// same (timestamp, sequence) pair could be produced twice.
+	if ts < g.lastTimestamp {
+		drift := time.Duration(g.lastTimestamp-ts) * time.Millisecond
+		if drift > maxClockRollback {
+			return 0, fmt.Errorf("%w by %s", ErrClockMovedBackwards, drift)
+		}
+		ts = g.waitUntilAfter(g.lastTimestamp - 1)
+	}
+
+	if ts == g.lastTimestamp {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted for this millisecond
+			ts = g.waitUntilAfter(ts)
+		}
+	} else {
+		g.sequence = 0
+	}
+
+	g.lastTimestamp = ts
+
+	return (ts-SnowflakeEpoch)<<(machineIDBits+sequenceBits) |
+		g.machineID<<sequenceBits |
+		g.sequence, nil
+}
+
+func (g *SnowflakeGenerator) currentMillis() int64 {
+	return g.now().UnixMilli()
+}
+
+func (g *SnowflakeGenerator) waitUntilAfter(ts int64) int64 {
+	now := g.currentMillis()
+	for now <= ts {
+		time.Sleep(100 * time.Microsecond)
+		now = g.currentMillis()
+	}
+	return now
+}
+
+// encodeID encodes n in base len(charset), left padded to length
+func encodeID(n uint64, charset string, length int) string {
+	base := uint64(len(charset))
+
+	var buf [16]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = charset[n%base]
+		n /= base
+	}
+
+	encoded := string(buf[i:])
+	if len(encoded) < length {
+		encoded = strings.Repeat(charset[:1], length-len(encoded)) + encoded
+	}
+	return encoded
+}
@@ -0,0 +1,50 @@
+package shortcode
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnowflakeCharset(t *testing.T) {
+	for _, charset := range []string{DefaultCharset, UnambiguousCharset} {
+		g, err := NewSnowflakeGeneratorWithCharset(1, charset)
+		if err != nil {
+			t.Fatalf("charset %q: %v", charset, err)
+		}
+
+		// Codes keep their width and order up to the largest timestamp
+		start := time.UnixMilli(SnowflakeEpoch)
+		previous := ""
+		for _, at := range []time.Time{start, start.Add(time.Hour), start.AddDate(30, 0, 0), start.AddDate(69, 0, 0)} {
+			g.now = func() time.Time { return at }
+
+			code, err := g.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(code) != SnowflakeCodeLength {
+				t.Errorf("code %q has length %d, want %d", code, len(code), SnowflakeCodeLength)
+			}
+			if strings.Trim(code, charset) != "" {
+				t.Errorf("code %q uses characters outside %q", code, charset)
+			}
+			if code <= previous {
+				t.Errorf("code %q does not sort after %q", code, previous)
+			}
+			previous = code
+		}
+	}
+}
+
+func TestSnowflakeRejectsCharset(t *testing.T) {
+	for _, charset := range []string{
+		"0123456789abcdef",        // too short to fit an ID
+		DefaultCharset[1:] + "0",  // out of order
+		DefaultCharset[:61] + "y", // repeated character
+	} {
+		if _, err := NewSnowflakeGeneratorWithCharset(1, charset); err == nil {
+			t.Errorf("NewSnowflakeGeneratorWithCharset(%q) succeeded", charset)
+		}
+	}
+}
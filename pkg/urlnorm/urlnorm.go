@@ -0,0 +1,302 @@
+// Package urlnorm canonicalizes URLs so that equivalent spellings of the same
+// destination compare equal.
+package urlnorm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultTrackingParams are query parameters that only carry campaign or
+// click attribution and never change the destination
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+	"igshid",
+	"yclid",
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Options controls the optional, lossy normalization steps
+type Options struct {
+	// SortQuery orders query parameters by key so that parameter order
+	// does not matter
+	SortQuery bool
+	// StripTrackingParams drops TrackingParams from the query
+	StripTrackingParams bool
+	// TrackingParams lists parameter names to strip; a trailing "*"
+	// matches by prefix. Defaults to DefaultTrackingParams.
+	TrackingParams []string
+}
+
+// Normalizer produces canonical forms of URLs
+type Normalizer struct {
+	sortQuery      bool
+	stripTracking  bool
+	trackingExact  map[string]bool
+	trackingPrefix []string
+}
+
+// New creates a Normalizer with the given options
+func New(opts Options) *Normalizer {
+	params := opts.TrackingParams
+	if params == nil {
+		params = DefaultTrackingParams
+	}
+
+	n := &Normalizer{
+		sortQuery:     opts.SortQuery,
+		stripTracking: opts.StripTrackingParams,
+		trackingExact: make(map[string]bool),
+	}
+	for _, param := range params {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			n.trackingPrefix = append(n.trackingPrefix, prefix)
+		} else {
+			n.trackingExact[param] = true
+		}
+	}
+
+	return n
+}
+
+// Normalize returns the canonical form of rawURL: lowercase scheme and host,
+// IDN hosts in punycode, no default port, dot segments removed and
+// percent-encoding normalized. The fragment is kept since client-side
+// routing depends on it.
+func (n *Normalizer) Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid URL: missing scheme or host")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	host, err := normalizeHost(scheme, u.Host)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(scheme)
+	b.WriteString("://")
+	if u.User != nil {
+		b.WriteString(u.User.String())
+		b.WriteByte('@')
+	}
+	b.WriteString(host)
+
+	path := removeDotSegments(normalizeEscapes(u.EscapedPath()))
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+
+	if query := n.normalizeQuery(u.RawQuery); query != "" {
+		b.WriteByte('?')
+		b.WriteString(query)
+	}
+
+	if u.Fragment != "" {
+		b.WriteByte('#')
+		b.WriteString(normalizeEscapes(u.EscapedFragment()))
+	}
+
+	return b.String(), nil
+}
+
+// Host returns the canonical form of a URL's host[:port] as used by
+// Normalize: lowercase, no trailing dot, IDN in punycode and no port when
+// it is the scheme's default.
+func Host(scheme, hostport string) (string, error) {
+	return normalizeHost(strings.ToLower(scheme), hostport)
+}
+
+func normalizeHost(scheme, hostport string) (string, error) {
+	host, port := hostport, ""
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		host, port = h, p
+	} else if strings.HasPrefix(hostport, "[") && strings.HasSuffix(hostport, "]") {
+		// IPv6 literal without a port; the brackets are added back below
+		host = hostport[1 : len(hostport)-1]
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if strings.Contains(host, ":") {
+		// IPv6 literal
+		host = "[" + host + "]"
+	} else if !isASCII(host) {
+		ascii, err := idna.Lookup.ToASCII(host)
+		if err != nil {
+			// Hosts that fail the lookup profile still get a stable form
+			if ascii, err = idna.Punycode.ToASCII(host); err != nil {
+				return "", fmt.Errorf("invalid host %q: %w", host, err)
+			}
+		}
+		host = ascii
+	}
+
+	if port != "" && port != defaultPorts[scheme] {
+		host = host + ":" + port
+	}
+
+	return host, nil
+}
+
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type param struct {
+		key, pair string
+	}
+
+	var params []param
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		pair = normalizeEscapes(pair)
+
+		key, _, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if n.stripTracking && n.isTracking(key) {
+			continue
+		}
+
+		params = append(params, param{key: key, pair: pair})
+	}
+
+	if n.sortQuery {
+		// Stable so repeated keys keep their relative order
+		sort.SliceStable(params, func(i, j int) bool {
+			return params[i].key < params[j].key
+		})
+	}
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.pair
+	}
+	return strings.Join(pairs, "&")
+}
+
+func (n *Normalizer) isTracking(key string) bool {
+	key = strings.ToLower(key)
+	if n.trackingExact[key] {
+		return true
+	}
+	for _, prefix := range n.trackingPrefix {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters and
+// uppercases the hex digits of the remaining escapes (RFC 3986 6.2.2)
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// removeDotSegments resolves "." and ".." path segments (RFC 3986 5.2.4)
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	out := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, segment)
+		}
+	}
+
+	return strings.Join(out, "/")
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
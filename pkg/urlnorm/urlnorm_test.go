@@ -0,0 +1,162 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	n := New(Options{})
+
+	for _, tc := range []struct {
+		name string
+		url  string
+		want string
+	}{
+		// Case and default ports
+		{"scheme and host case", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"https default port", "https://example.com:443/a", "https://example.com/a"},
+		{"http default port", "http://example.com:80/a", "http://example.com/a"},
+		{"default port of the other scheme", "http://example.com:443/a", "http://example.com:443/a"},
+		{"other port", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"empty port", "https://example.com:/a", "https://example.com/a"},
+		{"trailing dot", "https://example.com./a", "https://example.com/a"},
+		{"empty path", "https://example.com", "https://example.com/"},
+
+		// Percent-encoding
+		{"escape case", "https://example.com/a%2fb%c3%a9", "https://example.com/a%2Fb%C3%A9"},
+		{"unreserved decoded", "https://example.com/%7Euser/%41%2D%5F%2E", "https://example.com/~user/A-_."},
+		{"reserved kept", "https://example.com/a%3Fb%23c", "https://example.com/a%3Fb%23c"},
+		{"query escapes", "https://example.com/?q=%7e%2a", "https://example.com/?q=~%2A"},
+		{"fragment escapes", "https://example.com/#%7esection", "https://example.com/#~section"},
+
+		// Dot segments
+		{"single dots", "https://example.com/a/./b/.", "https://example.com/a/b/"},
+		{"double dots", "https://example.com/a/b/../c", "https://example.com/a/c"},
+		{"double dots at the end", "https://example.com/a/b/..", "https://example.com/a/"},
+		{"double dots above root", "https://example.com/../../a", "https://example.com/a"},
+		{"dots in names", "https://example.com/v1.2/file.tar.gz", "https://example.com/v1.2/file.tar.gz"},
+
+		// IDN
+		{"IDN", "https://Bücher.example/", "https://xn--bcher-kva.example/"},
+		{"IDN with port", "https://bücher.example:8080/", "https://xn--bcher-kva.example:8080/"},
+		{"punycode kept", "https://xn--bcher-kva.example/", "https://xn--bcher-kva.example/"},
+
+		// IPv6
+		{"IPv6", "http://[2001:db8::1]/x", "http://[2001:db8::1]/x"},
+		{"IPv6 default port", "http://[2001:db8::1]:80/x", "http://[2001:db8::1]/x"},
+		{"IPv6 other port", "http://[2001:DB8::1]:8080/x", "http://[2001:db8::1]:8080/x"},
+
+		// Kept as is without the optional steps
+		{"query order", "https://example.com/a?b=1&a=2", "https://example.com/a?b=1&a=2"},
+		{"tracking params", "https://example.com/?utm_source=x&id=1", "https://example.com/?utm_source=x&id=1"},
+		{"empty query pairs", "https://example.com/?a=1&&b=2&", "https://example.com/?a=1&b=2"},
+		{"userinfo", "https://user@example.com/", "https://user@example.com/"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := n.Normalize(tc.url)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tc.url, err)
+			}
+			if got != tc.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+// The pair from the canonicalization request only compares equal once query
+// sorting is enabled
+func TestNormalizeRequestExample(t *testing.T) {
+	const a, b = "HTTPS://Example.com:443/a?b=1&a=2", "https://example.com/a?a=2&b=1"
+
+	for _, tc := range []struct {
+		opts      Options
+		wantEqual bool
+	}{
+		{Options{}, false},
+		{Options{SortQuery: true}, true},
+	} {
+		n := New(tc.opts)
+		normalizedA, errA := n.Normalize(a)
+		normalizedB, errB := n.Normalize(b)
+		if errA != nil || errB != nil {
+			t.Fatal(errA, errB)
+		}
+		if equal := normalizedA == normalizedB; equal != tc.wantEqual {
+			t.Errorf("with %+v: %q and %q equal = %v, want %v",
+				tc.opts, normalizedA, normalizedB, equal, tc.wantEqual)
+		}
+	}
+}
+
+func TestNormalizeOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts Options
+		url  string
+		want string
+	}{
+		{"sort", Options{SortQuery: true},
+			"https://example.com/?b=1&a=2&c=3", "https://example.com/?a=2&b=1&c=3"},
+		{"sort keeps repeated keys in order", Options{SortQuery: true},
+			"https://example.com/?b=2&a=1&b=1", "https://example.com/?a=1&b=2&b=1"},
+		{"sort by decoded key", Options{SortQuery: true},
+			"https://example.com/?%62=1&a=2", "https://example.com/?a=2&b=1"},
+		{"strip default tracking params", Options{StripTrackingParams: true},
+			"https://example.com/?utm_source=x&id=1&UTM_Medium=y&fbclid=z&gclid=w",
+			"https://example.com/?id=1"},
+		{"strip only tracking params", Options{StripTrackingParams: true},
+			"https://example.com/?utm=1&source=2", "https://example.com/?utm=1&source=2"},
+		{"strip everything", Options{StripTrackingParams: true},
+			"https://example.com/a?utm_source=x", "https://example.com/a"},
+		{"custom tracking params", Options{StripTrackingParams: true, TrackingParams: []string{"ref", "cmp_*"}},
+			"https://example.com/?ref=a&cmp_id=b&utm_source=c", "https://example.com/?utm_source=c"},
+		{"sort and strip", Options{SortQuery: true, StripTrackingParams: true},
+			"https://example.com/?z=1&utm_campaign=x&a=2", "https://example.com/?a=2&z=1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := New(tc.opts).Normalize(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	for _, tc := range []struct {
+		scheme, host, want string
+	}{
+		{"http", "Example.COM", "example.com"},
+		{"HTTP", "example.com:80", "example.com"},
+		{"https", "example.com:443", "example.com"},
+		{"https", "example.com.:443", "example.com"},
+		{"http", "example.com:443", "example.com:443"},
+		{"http", "example.com:8080", "example.com:8080"},
+		{"https", "bücher.de", "xn--bcher-kva.de"},
+		{"http", "[::1]:80", "[::1]"},
+		{"http", "[::1]", "[::1]"},
+	} {
+		got, err := Host(tc.scheme, tc.host)
+		if err != nil || got != tc.want {
+			t.Errorf("Host(%q, %q) = %q, %v; want %q", tc.scheme, tc.host, got, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	n := New(Options{})
+
+	for _, rawURL := range []string{
+		"",
+		"example.com/path",
+		"/relative",
+		"https://exa mple.com/",
+		"http://[::1/",
+	} {
+		if got, err := n.Normalize(rawURL); err == nil {
+			t.Errorf("Normalize(%q) = %q, want an error", rawURL, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package useragent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a fixed-size least-recently-used cache of parsed agents
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Front is the most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	client Client
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lru) get(key string) (Client, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return Client{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).client, true
+}
+
+func (c *lru) add(key string, client Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).client = client
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, client: client})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
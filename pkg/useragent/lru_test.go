@@ -0,0 +1,38 @@
+package useragent
+
+import "testing"
+
+func TestLRU(t *testing.T) {
+	c := newLRU(2)
+	a := Client{DeviceType: DeviceDesktop}
+	b := Client{DeviceType: DeviceMobile}
+
+	c.add("a", a)
+	c.add("b", b)
+	if got, ok := c.get("a"); !ok || got != a {
+		t.Fatalf("get(a) = %+v, %v; want %+v", got, ok, a)
+	}
+
+	// "a" was used last, so "b" goes
+	c.add("c", Client{DeviceType: DeviceTablet})
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used entry b kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("entry %s evicted", key)
+		}
+	}
+
+	// Updating an entry replaces it without evicting anything
+	c.add("a", b)
+	if got, _ := c.get("a"); got != b {
+		t.Errorf("get(a) after update = %+v, want %+v", got, b)
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Error("entry c evicted by an update")
+	}
+	if c.order.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("cache holds %d/%d entries, want 2", c.order.Len(), len(c.entries))
+	}
+}
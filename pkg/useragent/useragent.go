@@ -0,0 +1,315 @@
+// Package useragent classifies User-Agent strings into device type, OS and
+// browser using rules in the uap-core regexes.yaml format.
+package useragent
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed regexes.yaml
+var defaultRules []byte
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Other is the family of clients no rule matches, as in uap-core
+const Other = "Other"
+
+const (
+	defaultCacheSize = 10000
+	// maxLength bounds the input to the regexes; longer agents are cut
+	maxLength = 512
+)
+
+type Config struct {
+	// RulesFile is a uap-core regexes.yaml; the embedded rules are used
+	// without one
+	RulesFile string
+	// CacheSize is the number of parsed agents kept, defaults to 10000
+	CacheSize int
+}
+
+// Client is a parsed User-Agent
+type Client struct {
+	DeviceType string // One of the Device constants, "" for an empty agent
+	Device     Device
+	OS         Software
+	Browser    Software
+}
+
+type Device struct {
+	Family string
+	Brand  string
+	Model  string
+}
+
+// Software is a browser or OS family and version
+type Software struct {
+	Family string
+	Major  string
+	Minor  string
+	Patch  string
+}
+
+// Version joins the version components that are set, e.g. "17.4"
+func (s Software) Version() string {
+	version := s.Major
+	for _, part := range []string{s.Minor, s.Patch} {
+		if part == "" {
+			break
+		}
+		version += "." + part
+	}
+	return version
+}
+
+type rulesFile struct {
+	UserAgentParsers []struct {
+		Regex             string `yaml:"regex"`
+		RegexFlag         string `yaml:"regex_flag"`
+		FamilyReplacement string `yaml:"family_replacement"`
+		V1Replacement     string `yaml:"v1_replacement"`
+		V2Replacement     string `yaml:"v2_replacement"`
+		V3Replacement     string `yaml:"v3_replacement"`
+	} `yaml:"user_agent_parsers"`
+	OSParsers []struct {
+		Regex           string `yaml:"regex"`
+		RegexFlag       string `yaml:"regex_flag"`
+		OSReplacement   string `yaml:"os_replacement"`
+		OSV1Replacement string `yaml:"os_v1_replacement"`
+		OSV2Replacement string `yaml:"os_v2_replacement"`
+		OSV3Replacement string `yaml:"os_v3_replacement"`
+	} `yaml:"os_parsers"`
+	DeviceParsers []struct {
+		Regex             string `yaml:"regex"`
+		RegexFlag         string `yaml:"regex_flag"`
+		DeviceReplacement string `yaml:"device_replacement"`
+		BrandReplacement  string `yaml:"brand_replacement"`
+		ModelReplacement  string `yaml:"model_replacement"`
+	} `yaml:"device_parsers"`
+}
+
+// softwareRule matches a browser or OS. Empty replacements fall back to
+// capture groups 1-4.
+type softwareRule struct {
+	re           *regexp.Regexp
+	replacements [4]string
+}
+
+type deviceRule struct {
+	re     *regexp.Regexp
+	family string
+	brand  string
+	model  string
+}
+
+// Parser parses User-Agents and caches the results. It is safe for
+// concurrent use.
+type Parser struct {
+	browsers []softwareRule
+	systems  []softwareRule
+	devices  []deviceRule
+	cache    *lru
+}
+
+func New(config Config) (*Parser, error) {
+	data := defaultRules
+	if config.RulesFile != "" {
+		var err error
+		if data, err = os.ReadFile(config.RulesFile); err != nil {
+			return nil, fmt.Errorf("failed to read user agent rules: %w", err)
+		}
+	}
+
+	var rules rulesFile
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse user agent rules: %w", err)
+	}
+
+	if config.CacheSize <= 0 {
+		config.CacheSize = defaultCacheSize
+	}
+	p := &Parser{cache: newLRU(config.CacheSize)}
+
+	for i, r := range rules.UserAgentParsers {
+		re, err := compile(r.Regex, r.RegexFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user_agent_parsers rule %d: %w", i, err)
+		}
+		p.browsers = append(p.browsers, softwareRule{re: re, replacements: [4]string{
+			r.FamilyReplacement, r.V1Replacement, r.V2Replacement, r.V3Replacement,
+		}})
+	}
+	for i, r := range rules.OSParsers {
+		re, err := compile(r.Regex, r.RegexFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid os_parsers rule %d: %w", i, err)
+		}
+		p.systems = append(p.systems, softwareRule{re: re, replacements: [4]string{
+			r.OSReplacement, r.OSV1Replacement, r.OSV2Replacement, r.OSV3Replacement,
+		}})
+	}
+	for i, r := range rules.DeviceParsers {
+		re, err := compile(r.Regex, r.RegexFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device_parsers rule %d: %w", i, err)
+		}
+		p.devices = append(p.devices, deviceRule{
+			re:     re,
+			family: r.DeviceReplacement,
+			brand:  r.BrandReplacement,
+			model:  r.ModelReplacement,
+		})
+	}
+
+	return p, nil
+}
+
+func compile(expr, flag string) (*regexp.Regexp, error) {
+	if flag == "i" {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// Parse classifies a User-Agent. Repeated agents are answered from the
+// cache without running the rules.
+func (p *Parser) Parse(userAgent string) Client {
+	if len(userAgent) > maxLength {
+		userAgent = userAgent[:maxLength]
+	}
+
+	if client, ok := p.cache.get(userAgent); ok {
+		return client
+	}
+
+	client := p.parse(userAgent)
+	p.cache.add(userAgent, client)
+	return client
+}
+
+func (p *Parser) parse(userAgent string) Client {
+	if strings.TrimSpace(userAgent) == "" {
+		return Client{
+			Device:  Device{Family: Other},
+			OS:      Software{Family: Other},
+			Browser: Software{Family: Other},
+		}
+	}
+
+	client := Client{
+		Device:  p.parseDevice(userAgent),
+		OS:      parseSoftware(p.systems, userAgent),
+		Browser: parseSoftware(p.browsers, userAgent),
+	}
+	client.DeviceType = deviceType(userAgent, &client)
+
+	return client
+}
+
+func parseSoftware(rules []softwareRule, userAgent string) Software {
+	for _, rule := range rules {
+		match := rule.re.FindStringSubmatchIndex(userAgent)
+		if match == nil {
+			continue
+		}
+
+		var parts [4]string
+		for i := range parts {
+			if rule.replacements[i] != "" {
+				parts[i] = expand(rule.replacements[i], userAgent, match)
+			} else {
+				parts[i] = group(userAgent, match, i+1)
+			}
+		}
+		return Software{Family: parts[0], Major: parts[1], Minor: parts[2], Patch: parts[3]}
+	}
+
+	return Software{Family: Other}
+}
+
+func (p *Parser) parseDevice(userAgent string) Device {
+	for _, rule := range p.devices {
+		match := rule.re.FindStringSubmatchIndex(userAgent)
+		if match == nil {
+			continue
+		}
+
+		device := Device{
+			Family: group(userAgent, match, 1),
+			Model:  group(userAgent, match, 1),
+			Brand:  expand(rule.brand, userAgent, match),
+		}
+		if rule.family != "" {
+			device.Family = expand(rule.family, userAgent, match)
+		}
+		if rule.model != "" {
+			device.Model = expand(rule.model, userAgent, match)
+		}
+		return device
+	}
+
+	return Device{Family: Other}
+}
+
+// group returns capture group n of a match, "" if it did not participate
+func group(s string, match []int, n int) string {
+	if 2*n+1 >= len(match) || match[2*n] < 0 {
+		return ""
+	}
+	return strings.TrimSpace(s[match[2*n]:match[2*n+1]])
+}
+
+// expand substitutes $1..$9 in a uap-core replacement
+func expand(template, s string, match []int) string {
+	if !strings.Contains(template, "$") {
+		return template
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] == '$' && i+1 < len(template) && template[i+1] >= '1' && template[i+1] <= '9' {
+			n, _ := strconv.Atoi(template[i+1 : i+2])
+			b.WriteString(group(s, match, n))
+			i++
+			continue
+		}
+		b.WriteByte(template[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var (
+	tabletPattern = regexp.MustCompile(`(?i)tablet|ipad|kindle|silk|playbook|\bKF[A-Z]{2,4}\b`)
+	mobilePattern = regexp.MustCompile(`(?i)mobi|iphone|ipod|windows phone|kaios|blackberry|opera mini`)
+)
+
+// deviceType buckets a parsed agent. uap-core marks crawlers with the
+// "Spider" device; the rest is decided by well-known tablet and mobile
+// tokens, with Android agents lacking "Mobile" counted as tablets.
+func deviceType(userAgent string, client *Client) string {
+	switch {
+	case client.Device.Family == "Spider":
+		return DeviceBot
+	case tabletPattern.MatchString(userAgent):
+		return DeviceTablet
+	case mobilePattern.MatchString(userAgent):
+		return DeviceMobile
+	case client.OS.Family == "Android":
+		return DeviceTablet
+	default:
+		return DeviceDesktop
+	}
+}
@@ -0,0 +1,300 @@
+package useragent
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		userAgent  string
+		deviceType string
+		browser    string // Family and version
+		os         string
+		device     string // Family/Brand/Model
+	}{
+		// Desktop
+		{
+			"Chrome on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.91 Safari/537.36",
+			DeviceDesktop, "Chrome 124.0.6367", "Windows 10", "Other//",
+		},
+		{
+			"Edge on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80",
+			DeviceDesktop, "Edge 124.0.2478", "Windows 10", "Other//",
+		},
+		{
+			"Safari on macOS",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			DeviceDesktop, "Safari 17.4", "Mac OS X 10.15.7", "Mac/Apple/Mac",
+		},
+		{
+			"Firefox on Ubuntu",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			DeviceDesktop, "Firefox 125.0", "Ubuntu", "Other//",
+		},
+
+		// Mobile
+		{
+			"Safari on iPhone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1",
+			DeviceMobile, "Mobile Safari 17.4.1", "iOS 17.4.1", "iPhone/Apple/iPhone",
+		},
+		{
+			"Chrome on Pixel",
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			DeviceMobile, "Chrome Mobile 124.0.6367", "Android 14", "Pixel 8/Google/Pixel 8",
+		},
+		{
+			"Samsung Internet",
+			"Mozilla/5.0 (Linux; Android 13; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			DeviceMobile, "Samsung Internet 24.0", "Android 13", "Samsung SM-S918B/Samsung/SM-S918B",
+		},
+		{
+			"Android WebView",
+			"Mozilla/5.0 (Linux; Android 13; SM-A536B Build/TP1A.220624.014; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/123.0.6312.118 Mobile Safari/537.36",
+			DeviceMobile, "Chrome Mobile WebView 123.0.6312", "Android 13", "Samsung SM-A536B/Samsung/SM-A536B",
+		},
+		{
+			// "CUBOT" must not be taken for a crawler
+			"Cubot phone",
+			"Mozilla/5.0 (Linux; Android 12; CUBOT P60 Build/SP1A.210812.016) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			DeviceMobile, "Chrome Mobile 120.0.6099", "Android 12", "CUBOT P60/Cubot/P60",
+		},
+
+		// Tablet
+		{
+			"Safari on iPad",
+			"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			DeviceTablet, "Mobile Safari 16.6", "iOS 16.6", "iPad/Apple/iPad",
+		},
+		{
+			"Android without Mobile",
+			"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			DeviceTablet, "Chrome 124.0.0", "Android 13", "Samsung SM-X700/Samsung/SM-X700",
+		},
+		{
+			"Kindle Fire",
+			"Mozilla/5.0 (Linux; Android 9; KFTRWI) AppleWebKit/537.36 (KHTML, like Gecko) Silk/124.2.1 like Chrome/124.0.6367.82 Safari/537.36",
+			DeviceTablet, "Chrome 124.0.6367", "Android 9", "Kindle/Amazon/KFTRWI",
+		},
+
+		// Bots
+		{
+			"Googlebot",
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			DeviceBot, "Googlebot 2.1", "Other", "Spider/Spider/Desktop",
+		},
+		{
+			// Crawlers win over the mobile tokens they imitate
+			"Googlebot smartphone",
+			"Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.91 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			DeviceBot, "Googlebot 2.1", "Android 6.0.1", "Spider/Spider/Desktop",
+		},
+		{
+			"curl",
+			"curl/8.4.0",
+			DeviceBot, "curl 8.4.0", "Other", "Spider/Spider/Desktop",
+		},
+		{
+			"unknown crawler",
+			"Mozilla/5.0 (compatible; ExampleCrawler/3.2; +https://crawler.example/)",
+			DeviceBot, "ExampleCrawler 3.2", "Other", "Spider/Spider/Desktop",
+		},
+
+		// Nothing to parse
+		{"empty", "", "", "Other", "Other", "Other//"},
+		{"blank", "   ", "", "Other", "Other", "Other//"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := p.Parse(tc.userAgent)
+
+			if client.DeviceType != tc.deviceType {
+				t.Errorf("DeviceType = %q, want %q", client.DeviceType, tc.deviceType)
+			}
+			if got := describe(client.Browser); got != tc.browser {
+				t.Errorf("Browser = %q, want %q", got, tc.browser)
+			}
+			if got := describe(client.OS); got != tc.os {
+				t.Errorf("OS = %q, want %q", got, tc.os)
+			}
+			device := client.Device.Family + "/" + client.Device.Brand + "/" + client.Device.Model
+			if device != tc.device {
+				t.Errorf("Device = %q, want %q", device, tc.device)
+			}
+		})
+	}
+}
+
+func describe(s Software) string {
+	if version := s.Version(); version != "" {
+		return s.Family + " " + version
+	}
+	return s.Family
+}
+
+func TestParseRulesFile(t *testing.T) {
+	rules := `
+user_agent_parsers:
+  - regex: 'foo(bar)/(\d+)'
+    regex_flag: 'i'
+    family_replacement: 'Foo $1'
+    v1_replacement: 'v$2'
+    v2_replacement: '$3'
+  - regex: '(Widget)/(\d+)\.(\d+)(?:\.(\d+))?'
+os_parsers:
+  - regex: '(Plan9) (\d+)'
+    os_replacement: '$1 OS'
+device_parsers:
+  - regex: '(Gizmo) ([A-Z]+\d+)'
+    device_replacement: '$1-$2'
+    brand_replacement: 'Acme'
+    model_replacement: '$2'
+  - regex: '(Thing)'
+`
+	path := filepath.Join(t.TempDir(), "regexes.yaml")
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(Config{RulesFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		userAgent string
+		want      Client
+	}{
+		{
+			// regex_flag "i", $n in replacements, unset groups expand to ""
+			"FOOBAR/7 (Plan9 4; Gizmo XK42)",
+			Client{
+				DeviceType: DeviceDesktop,
+				Device:     Device{Family: "Gizmo-XK42", Brand: "Acme", Model: "XK42"},
+				OS:         Software{Family: "Plan9 OS", Major: "4"},
+				Browser:    Software{Family: "Foo BAR", Major: "v7"},
+			},
+		},
+		{
+			// No replacements: capture groups 1-4, and group 1 as the model
+			"Widget/1.2.3 (Thing)",
+			Client{
+				DeviceType: DeviceDesktop,
+				Device:     Device{Family: "Thing", Model: "Thing"},
+				OS:         Software{Family: Other},
+				Browser:    Software{Family: "Widget", Major: "1", Minor: "2", Patch: "3"},
+			},
+		},
+		{
+			// Rules without the flag are case sensitive
+			"widget/1.2 (thing)",
+			Client{
+				DeviceType: DeviceDesktop,
+				Device:     Device{Family: Other},
+				OS:         Software{Family: Other},
+				Browser:    Software{Family: Other},
+			},
+		},
+	} {
+		if got := p.Parse(tc.userAgent); got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.userAgent, got, tc.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	for _, rules := range []string{
+		"user_agent_parsers: [not yaml",
+		"user_agent_parsers:\n  - regex: '(unclosed'\n",
+		"os_parsers:\n  - regex: '(?<=lookbehind)'\n",
+		"device_parsers:\n  - regex: '['\n",
+	} {
+		path := filepath.Join(t.TempDir(), "regexes.yaml")
+		if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(Config{RulesFile: path}); err == nil {
+			t.Errorf("New with rules %q succeeded", rules)
+		}
+	}
+
+	if _, err := New(Config{RulesFile: filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("New with a missing rules file succeeded")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	re := regexp.MustCompile(`(\w+) (\w+)(?: (\w+))?`)
+	s := "Acme Phone"
+	match := re.FindStringSubmatchIndex(s)
+
+	for _, tc := range []struct {
+		template string
+		want     string
+	}{
+		{"Fixed", "Fixed"},
+		{"$1", "Acme"},
+		{"$2 by $1", "Phone by Acme"},
+		{"$1 $3", "Acme"}, // unset group, trailing space trimmed
+		{"$9", ""},        // no such group
+		{"$0 and $x", "$0 and $x"},
+		{"US$", "US$"},
+	} {
+		if got := expand(tc.template, s, match); got != tc.want {
+			t.Errorf("expand(%q) = %q, want %q", tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestSoftwareVersion(t *testing.T) {
+	for _, tc := range []struct {
+		software Software
+		want     string
+	}{
+		{Software{}, ""},
+		{Software{Major: "17"}, "17"},
+		{Software{Major: "17", Minor: "4"}, "17.4"},
+		{Software{Major: "17", Minor: "4", Patch: "1"}, "17.4.1"},
+		{Software{Major: "17", Patch: "1"}, "17"},
+	} {
+		if got := tc.software.Version(); got != tc.want {
+			t.Errorf("%+v.Version() = %q, want %q", tc.software, got, tc.want)
+		}
+	}
+}
+
+func TestParseCaches(t *testing.T) {
+	p, err := New(Config{CacheSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const agent = "curl/8.4.0"
+	first := p.Parse(agent)
+	if cached, ok := p.cache.get(agent); !ok || cached != first {
+		t.Fatalf("cache has %+v, %v after Parse; want %+v", cached, ok, first)
+	}
+
+	// Answers come from the cache, not the rules
+	p.browsers = nil
+	if got := p.Parse(agent); got != first {
+		t.Errorf("cached Parse = %+v, want %+v", got, first)
+	}
+
+	// Long agents are cut before parsing and caching
+	long := agent + strings.Repeat(" x", maxLength)
+	p.Parse(long)
+	if _, ok := p.cache.get(long[:maxLength]); !ok {
+		t.Error("truncated agent not cached")
+	}
+}
@@ -0,0 +1,329 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Deceptive URL checks reported in RiskReason.Check
+const (
+	RiskMixedScript    = "mixed_script"
+	RiskConfusable     = "confusable"
+	RiskBrandLookalike = "brand_lookalike"
+	RiskBrandSubdomain = "brand_in_subdomain"
+	RiskUserinfo       = "userinfo"
+	RiskSubdomainDepth = "subdomain_depth"
+)
+
+// MaxRiskScore is the highest score a RiskReport can have
+const MaxRiskScore = 100
+
+// DefaultProtectedDomains are brands commonly impersonated in phishing links
+var DefaultProtectedDomains = []string{
+	"apple.com",
+	"google.com",
+	"microsoft.com",
+	"paypal.com",
+	"amazon.com",
+	"facebook.com",
+	"instagram.com",
+	"netflix.com",
+	"linkedin.com",
+	"github.com",
+}
+
+// RiskReason is one finding of the deceptive URL analysis
+type RiskReason struct {
+	Check  string `json:"check"`
+	Detail string `json:"detail"`
+	Score  int    `json:"score"`
+}
+
+// RiskReport scores how likely a URL is to disguise its real destination.
+// Score is the sum of the reasons' scores, capped at MaxRiskScore.
+type RiskReport struct {
+	Score   int          `json:"score"`
+	Reasons []RiskReason `json:"reasons,omitempty"`
+}
+
+func (r *RiskReport) add(check string, score int, detail string) {
+	r.Reasons = append(r.Reasons, RiskReason{Check: check, Detail: detail, Score: score})
+	r.Score = min(r.Score+score, MaxRiskScore)
+}
+
+// Analyze scores rawURL for IDN homographs, brand look-alikes, userinfo
+// tricks and excessive subdomain depth
+func (v *DefaultValidator) Analyze(rawURL string) (*RiskReport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	report := &RiskReport{}
+
+	// https://paypal.com@evil.example/ reads as paypal.com but goes to
+	// evil.example. Validate rejects URLs with credentials outright, so
+	// this only scores URLs passed to Analyze without Validate; CreateURL
+	// and UpdateURL never get here with userinfo.
+	if u.User != nil {
+		score := 40
+		if strings.Contains(u.User.Username(), ".") {
+			// Userinfo dressed up as a hostname
+			score = 60
+		}
+		report.add(RiskUserinfo, score,
+			fmt.Sprintf("userinfo %q precedes the real host", u.User.Username()))
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || isIPHost(host) {
+		return report, nil
+	}
+
+	unicodeHost, err := idna.Punycode.ToUnicode(host)
+	if err != nil {
+		unicodeHost = host
+	}
+
+	for _, label := range strings.Split(unicodeHost, ".") {
+		if isASCII(label) {
+			continue
+		}
+		scripts := labelScripts(label)
+		if !allowedScriptMix(scripts) {
+			report.add(RiskMixedScript, 50,
+				fmt.Sprintf("label %q mixes %s", label, strings.Join(scripts, ", ")))
+		} else if skeleton := confusableSkeleton(label); isASCII(skeleton) {
+			report.add(RiskConfusable, 30,
+				fmt.Sprintf("label %q is confusable with %q", label, skeleton))
+		}
+	}
+
+	v.checkBrands(report, host, unicodeHost)
+
+	if depth := subdomainDepth(host); depth > v.maxSubdomainDepth {
+		report.add(RiskSubdomainDepth, 20,
+			fmt.Sprintf("%d subdomain levels (max %d)", depth, v.maxSubdomainDepth))
+	}
+
+	return report, nil
+}
+
+// checkBrands compares the host, and its confusable skeleton, against the
+// protected domains
+func (v *DefaultValidator) checkBrands(report *RiskReport, host, unicodeHost string) {
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return
+	}
+
+	skeleton := confusableSkeleton(unicodeHost)
+	skeletonRegistrable, err := publicsuffix.EffectiveTLDPlusOne(skeleton)
+	if err != nil {
+		skeletonRegistrable = skeleton
+	}
+	subdomains := strings.Split(strings.TrimSuffix(skeleton, skeletonRegistrable), ".")
+	name := registrableName(skeletonRegistrable)
+
+	for _, protected := range v.protectedDomains {
+		if registrable == protected {
+			// The genuine domain
+			return
+		}
+	}
+
+	for _, protected := range v.protectedDomains {
+		brand := registrableName(protected)
+
+		switch {
+		case skeletonRegistrable == protected:
+			report.add(RiskBrandLookalike, 60,
+				fmt.Sprintf("%s imitates %s", registrable, protected))
+			return
+		case containsLabel(subdomains, brand):
+			report.add(RiskBrandSubdomain, 60,
+				fmt.Sprintf("%s appears in the subdomain of %s", brand, registrable))
+			return
+		case len(brand) >= 5 && levenshtein(name, brand) <= 1:
+			report.add(RiskBrandLookalike, 50,
+				fmt.Sprintf("%s is one edit away from %s", registrable, protected))
+			return
+		case len(brand) >= 5 && strings.Contains(name, brand):
+			report.add(RiskBrandLookalike, 30,
+				fmt.Sprintf("%s embeds the brand %s", registrable, brand))
+			return
+		}
+	}
+}
+
+// labelScripts lists the Unicode scripts used by label, ignoring Common and
+// Inherited characters such as digits and hyphens
+func labelScripts(label string) []string {
+	var scripts []string
+	seen := make(map[string]bool)
+	for _, r := range label {
+		script := runeScript(r)
+		if script == "" || seen[script] {
+			continue
+		}
+		seen[script] = true
+		scripts = append(scripts, script)
+	}
+	return scripts
+}
+
+func runeScript(r rune) string {
+	if r <= unicode.MaxASCII {
+		if unicode.IsLetter(r) {
+			return "Latin"
+		}
+		return ""
+	}
+	if unicode.Is(unicode.Common, r) || unicode.Is(unicode.Inherited, r) {
+		return ""
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// allowedScriptMix implements the TR39 "Highly Restrictive" profile: one
+// script, or Latin combined with one of the CJK script sets
+func allowedScriptMix(scripts []string) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	allowed := [][]string{
+		{"Latin", "Han", "Hiragana", "Katakana"},
+		{"Latin", "Han", "Bopomofo"},
+		{"Latin", "Han", "Hangul"},
+	}
+	for _, set := range allowed {
+		if subsetOf(scripts, set) {
+			return true
+		}
+	}
+	return false
+}
+
+func subsetOf(scripts, set []string) bool {
+	for _, script := range scripts {
+		found := false
+		for _, s := range set {
+			if s == script {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// confusables maps characters to the Latin letters they are visually
+// confusable with. It is the subset of the TR39 confusables table relevant to
+// lowercase hostnames.
+var confusables = map[rune]string{
+	// Cyrillic
+	'а': "a", 'в': "b", 'ь': "b", 'с': "c", 'ԁ': "d", 'е': "e", 'ё': "e",
+	'ғ': "f", 'ԍ': "g", 'һ': "h", 'і': "i", 'ї': "i", 'ј': "j", 'к': "k",
+	'ӏ': "l", 'м': "m", 'п': "n", 'о': "o", 'р': "p", 'ԛ': "q", 'г': "r",
+	'ѕ': "s", 'т': "t", 'ц': "u", 'ѵ': "v", 'ԝ': "w", 'ш': "w", 'х': "x",
+	'у': "y", 'ү': "y", 'з': "3",
+	// Greek
+	'α': "a", 'β': "b", 'ϲ': "c", 'ε': "e", 'η': "n", 'ι': "i", 'κ': "k",
+	'ν': "v", 'ο': "o", 'ρ': "p", 'τ': "t", 'υ': "u", 'χ': "x", 'γ': "y",
+	'ω': "w",
+	// Latin look-alikes
+	'ı': "i", 'ɩ': "i", 'ɑ': "a", 'ɡ': "g", 'ǀ': "l", 'ℓ': "l", 'ɵ': "o",
+	'ø': "o", 'đ': "d", 'ħ': "h", 'ŀ': "l", 'ł': "l",
+	// Digits
+	'0': "o", '1': "l",
+}
+
+// confusableSkeleton maps every confusable character in s to its Latin
+// prototype
+func confusableSkeleton(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if prototype, ok := confusables[r]; ok {
+			b.WriteString(prototype)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// registrableName is the registrable domain without its public suffix,
+// "paypal" for "paypal.co.uk"
+func registrableName(registrable string) string {
+	suffix, _ := publicsuffix.PublicSuffix(registrable)
+	return strings.TrimSuffix(strings.TrimSuffix(registrable, suffix), ".")
+}
+
+func subdomainDepth(host string) int {
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil || registrable == host {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(host, registrable), ".")
+}
+
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func isIPHost(host string) bool {
+	_, ok := parseIPLiteral(host)
+	return ok || strings.Contains(host, ":")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
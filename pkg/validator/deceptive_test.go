@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	v := NewValidator(Config{})
+
+	for _, tc := range []struct {
+		name   string
+		url    string
+		checks []string
+		score  int
+	}{
+		// Genuine and unrelated domains
+		{"brand", "https://apple.com/iphone", nil, 0},
+		{"brand subdomain", "https://www.paypal.com/signin", nil, 0},
+		{"unrelated", "https://example.com/", nil, 0},
+		{"IP literal", "http://93.184.216.34/", nil, 0},
+		{"single script IDN", "https://bücher.de/", nil, 0},
+		{"Latin with Han", "https://abc日本.jp/", nil, 0},
+
+		// Homographs
+		{"Cyrillic a", "https://аpple.com", []string{RiskMixedScript, RiskBrandLookalike}, 100},
+		{"Cyrillic a in punycode", "https://xn--pple-43d.com", []string{RiskMixedScript, RiskBrandLookalike}, 100},
+		{"Greek o", "https://gοogle.com/", []string{RiskMixedScript, RiskBrandLookalike}, 100},
+		{"all Cyrillic", "https://аррӏе.com/", []string{RiskConfusable, RiskBrandLookalike}, 90},
+		{"digit for letter", "https://paypa1.com/", []string{RiskBrandLookalike}, 60},
+
+		// Brand look-alikes
+		{"brand in subdomain", "https://paypal.com.secure-login.xyz", []string{RiskBrandSubdomain}, 60},
+		{"brand label in subdomain", "https://login.microsoft.evil.example/", []string{RiskBrandSubdomain}, 60},
+		{"one edit away", "https://paypall.com/", []string{RiskBrandLookalike}, 50},
+		{"embedded brand", "https://mypaypal-account.com/", []string{RiskBrandLookalike}, 30},
+		{"short brands need exact matches", "https://appel.com/", nil, 0},
+
+		// Userinfo, for callers that skip Validate
+		{"userinfo", "https://user@example.com/", []string{RiskUserinfo}, 40},
+		{"userinfo as host", "https://paypal.com@evil.example/", []string{RiskUserinfo}, 60},
+
+		// Subdomain depth
+		{"three levels", "https://a.b.c.example.com/", nil, 0},
+		{"four levels", "https://a.b.c.d.example.com/", []string{RiskSubdomainDepth}, 20},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := v.Analyze(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var checks []string
+			for _, reason := range report.Reasons {
+				checks = append(checks, reason.Check)
+			}
+			if !reflect.DeepEqual(checks, tc.checks) || report.Score != tc.score {
+				t.Errorf("Analyze(%q) = %d %v, want %d %v (reasons %+v)",
+					tc.url, report.Score, checks, tc.score, tc.checks, report.Reasons)
+			}
+		})
+	}
+}
+
+func TestAnalyzeProtectedDomains(t *testing.T) {
+	v := NewValidator(Config{ProtectedDomains: []string{"example-bank.co.uk"}, MaxSubdomainDepth: 1})
+
+	for _, tc := range []struct {
+		url   string
+		score int
+	}{
+		{"https://example-bank.co.uk/", 0},
+		{"https://online.example-bank.co.uk/", 0},
+		{"https://example-bamk.co.uk/", 50},
+		{"https://paypa1.com/", 0}, // not protected here
+		{"https://a.b.example.com/", 20},
+	} {
+		report, err := v.Analyze(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Score != tc.score {
+			t.Errorf("Analyze(%q) score = %d, want %d (reasons %+v)", tc.url, report.Score, tc.score, report.Reasons)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"paypal", "paypal", 0},
+		{"paypal", "paypall", 1},
+		{"paypal", "paypa", 1},
+		{"paypal", "pypal", 1},
+		{"paypal", "paypol", 1},
+		{"google", "gogle", 1},
+		{"kitten", "sitting", 3},
+		{"аpple", "apple", 1}, // runes, not bytes
+	} {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+	"gopkg.in/yaml.v3"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/reloader"
+)
+
+const (
+	PolicyModeDeny  = "deny"  // reject hosts matching a rule
+	PolicyModeAllow = "allow" // reject hosts not matching any rule
+)
+
+// DefaultDeniedDomains prevents recursive shortening through other shorteners
+var DefaultDeniedDomains = []string{"domain:bit.ly", "domain:tinyurl.com"}
+
+// DomainPolicyConfig configures host allow/deny rules. Rules are either an
+// exact host ("example.com"), a wildcard matching any subdomain
+// ("*.example.com") or a registrable domain matched with the public suffix
+// list ("domain:example.co.uk" covers example.co.uk and all its subdomains).
+type DomainPolicyConfig struct {
+	Mode  string
+	Rules []string
+	// File, if set, holds "mode" and "rules" in YAML and takes precedence
+	// over Mode and Rules
+	File            string
+	RefreshInterval time.Duration
+}
+
+type domainRuleKind int
+
+const (
+	ruleExact domainRuleKind = iota
+	ruleWildcard
+	ruleRegistrable
+)
+
+type domainRule struct {
+	raw   string
+	kind  domainRuleKind
+	value string
+}
+
+type domainPolicyState struct {
+	mode  string
+	rules []domainRule
+}
+
+// DomainPolicy decides which destination hosts may be shortened. Rules can
+// be swapped at runtime without blocking validation.
+type DomainPolicy struct {
+	config DomainPolicyConfig
+	state  atomic.Pointer[domainPolicyState]
+}
+
+func NewDomainPolicy(config DomainPolicyConfig) (*DomainPolicy, error) {
+	p := &DomainPolicy{config: config}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload re-reads the policy file, or re-applies the configured rules when
+// there is none. On error the current rules stay in effect.
+func (p *DomainPolicy) Reload() error {
+	if p.config.File == "" {
+		return p.SetRules(p.config.Mode, p.config.Rules)
+	}
+
+	data, err := os.ReadFile(p.config.File)
+	if err != nil {
+		return fmt.Errorf("failed to read domain policy: %w", err)
+	}
+
+	var file struct {
+		Mode  string   `yaml:"mode"`
+		Rules []string `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse domain policy %s: %w", p.config.File, err)
+	}
+
+	return p.SetRules(file.Mode, file.Rules)
+}
+
+// SetRules replaces the active mode and rules
+func (p *DomainPolicy) SetRules(mode string, rules []string) error {
+	if mode == "" {
+		mode = PolicyModeDeny
+	}
+	if mode != PolicyModeDeny && mode != PolicyModeAllow {
+		return fmt.Errorf("unknown domain policy mode %q", mode)
+	}
+
+	state := &domainPolicyState{mode: mode}
+	for _, raw := range rules {
+		rule, err := parseDomainRule(raw)
+		if err != nil {
+			return err
+		}
+		state.rules = append(state.rules, rule)
+	}
+
+	p.state.Store(state)
+	return nil
+}
+
+// Watch reloads the policy file when it changes or on SIGHUP until ctx is
+// cancelled. Reload errors are passed to onError.
+func (p *DomainPolicy) Watch(ctx context.Context, onError func(error)) {
+	if p.config.File == "" {
+		return
+	}
+
+	reloader.Watch(ctx, p.config.RefreshInterval, []string{p.config.File}, func() {
+		if err := p.Reload(); err != nil {
+			onError(err)
+		}
+	})
+}
+
+// Check returns a *ValidationError naming the matched rule if host is not
+// allowed by the policy
+func (p *DomainPolicy) Check(host string) error {
+	state := p.state.Load()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	var matched *domainRule
+	for i := range state.rules {
+		if state.rules[i].matches(host) {
+			matched = &state.rules[i]
+			break
+		}
+	}
+
+	switch {
+	case state.mode == PolicyModeDeny && matched != nil:
+		return &ValidationError{
+			Rule:   RuleDomainPolicy,
+			Reason: fmt.Sprintf("domain %s is blocked by rule %s", host, matched.raw),
+			Match:  matched.raw,
+		}
+	case state.mode == PolicyModeAllow && matched == nil:
+		return &ValidationError{
+			Rule:   RuleDomainPolicy,
+			Reason: fmt.Sprintf("domain %s is not on the allowlist", host),
+		}
+	}
+
+	return nil
+}
+
+func parseDomainRule(raw string) (domainRule, error) {
+	value := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+	rule := domainRule{raw: raw}
+
+	switch {
+	case strings.HasPrefix(value, "domain:"):
+		rule.kind = ruleRegistrable
+		rule.value = strings.TrimPrefix(value, "domain:")
+		registrable, err := publicsuffix.EffectiveTLDPlusOne(rule.value)
+		if err != nil || registrable != rule.value {
+			return rule, fmt.Errorf("domain policy rule %q is not a registrable domain", raw)
+		}
+	case strings.HasPrefix(value, "*."):
+		rule.kind = ruleWildcard
+		rule.value = strings.TrimPrefix(value, "*")
+	default:
+		rule.kind = ruleExact
+		rule.value = value
+	}
+
+	if rule.value == "" || rule.value == "." || strings.Contains(rule.value, "*") {
+		return rule, fmt.Errorf("invalid domain policy rule %q", raw)
+	}
+
+	return rule, nil
+}
+
+func (r *domainRule) matches(host string) bool {
+	switch r.kind {
+	case ruleWildcard:
+		return strings.HasSuffix(host, r.value)
+	case ruleRegistrable:
+		registrable, err := publicsuffix.EffectiveTLDPlusOne(host)
+		return err == nil && registrable == r.value
+	default:
+		return host == r.value
+	}
+}
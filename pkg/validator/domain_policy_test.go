@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDomainPolicyDeny(t *testing.T) {
+	policy, err := NewDomainPolicy(DomainPolicyConfig{
+		Mode: PolicyModeDeny,
+		Rules: []string{
+			"domain:bit.ly",
+			"domain:example.co.uk",
+			"*.tracker.example",
+			"Exact.Example.",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		host  string
+		match string // rule that blocks host, "" when allowed
+	}{
+		// Registrable domain: the domain and all its subdomains
+		{"bit.ly", "domain:bit.ly"},
+		{"BIT.LY.", "domain:bit.ly"},
+		{"www.bit.ly", "domain:bit.ly"},
+		{"a.b.bit.ly", "domain:bit.ly"},
+		{"notbit.ly", ""},
+		{"notbit.ly.example.com", ""},
+		{"bit.ly.example.com", ""},
+		{"bit.lyx", ""},
+		{"example.co.uk", "domain:example.co.uk"},
+		{"shop.example.co.uk", "domain:example.co.uk"},
+		{"co.uk", ""},
+		{"other.co.uk", ""},
+
+		// Wildcard: subdomains only
+		{"a.tracker.example", "*.tracker.example"},
+		{"a.b.tracker.example", "*.tracker.example"},
+		{"tracker.example", ""},
+		{"eviltracker.example", ""},
+
+		// Exact host only
+		{"exact.example", "Exact.Example."},
+		{"www.exact.example", ""},
+		{"exact.example.com", ""},
+	} {
+		t.Run(tc.host, func(t *testing.T) {
+			err := policy.Check(tc.host)
+			if tc.match == "" {
+				if err != nil {
+					t.Errorf("Check(%q) = %v, want allowed", tc.host, err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Rule != RuleDomainPolicy ||
+				validationErr.Match != tc.match {
+				t.Errorf("Check(%q) = %v, want blocked by %q", tc.host, err, tc.match)
+			}
+		})
+	}
+}
+
+func TestDomainPolicyAllow(t *testing.T) {
+	policy, err := NewDomainPolicy(DomainPolicyConfig{
+		Mode:  PolicyModeAllow,
+		Rules: []string{"domain:example.com", "*.cdn.example", "docs.example.org"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		host    string
+		allowed bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true},
+		{"notexample.com", false},
+		{"example.com.evil.net", false},
+		{"img.cdn.example", true},
+		{"cdn.example", false},
+		{"docs.example.org", true},
+		{"example.org", false},
+	} {
+		t.Run(tc.host, func(t *testing.T) {
+			err := policy.Check(tc.host)
+			if allowed := err == nil; allowed != tc.allowed {
+				t.Errorf("Check(%q) = %v, want allowed %v", tc.host, err, tc.allowed)
+			}
+			if err != nil && ruleOf(err) != RuleDomainPolicy {
+				t.Errorf("Check(%q) rule = %q, want %q", tc.host, ruleOf(err), RuleDomainPolicy)
+			}
+		})
+	}
+}
+
+func TestDomainPolicyRejectsInvalidRules(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		rule string
+	}{
+		{PolicyModeDeny, "domain:co.uk"},           // a public suffix
+		{PolicyModeDeny, "domain:www.example.com"}, // not the registrable domain
+		{PolicyModeDeny, "*"},
+		{PolicyModeDeny, "*."},
+		{PolicyModeDeny, "a*.example.com"},
+		{PolicyModeDeny, " "},
+		{"block", "example.com"},
+	} {
+		if _, err := NewDomainPolicy(DomainPolicyConfig{Mode: tc.mode, Rules: []string{tc.rule}}); err == nil {
+			t.Errorf("NewDomainPolicy(%q, %q) succeeded", tc.mode, tc.rule)
+		}
+	}
+
+	// The default mode is deny
+	policy, err := NewDomainPolicy(DomainPolicyConfig{Rules: DefaultDeniedDomains})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Check("tinyurl.com") == nil || policy.Check("example.com") != nil {
+		t.Error("default mode does not deny the configured rules")
+	}
+}
+
+func TestDomainPolicyReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writePolicy("mode: deny\nrules:\n  - domain:bit.ly\n")
+	policy, err := NewDomainPolicy(DomainPolicyConfig{
+		// The file takes precedence over these
+		Mode:  PolicyModeAllow,
+		Rules: []string{"example.com"},
+		File:  path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Check("bit.ly") == nil || policy.Check("other.example") != nil {
+		t.Fatal("policy does not follow the file")
+	}
+
+	writePolicy("mode: allow\nrules:\n  - \"*.example.com\"\n")
+	if err := policy.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Check("bit.ly") == nil || policy.Check("www.example.com") != nil {
+		t.Error("reloaded allowlist not in effect")
+	}
+
+	// Broken files keep the last good policy
+	for _, content := range []string{
+		"mode: [not a string\n",
+		"mode: sometimes\n",
+		"rules:\n  - domain:co.uk\n",
+	} {
+		writePolicy(content)
+		if err := policy.Reload(); err == nil {
+			t.Errorf("Reload of %q succeeded", content)
+		}
+		if policy.Check("bit.ly") == nil || policy.Check("www.example.com") != nil {
+			t.Errorf("policy changed by a failed reload of %q", content)
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Reload(); err == nil {
+		t.Error("Reload of a missing file succeeded")
+	}
+	if policy.Check("www.example.com") != nil {
+		t.Error("policy changed by a failed reload of a missing file")
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrThreatConfirmation is returned when a threat list hash prefix hit
+// could not be checked against the full hashes. IsSafe reports such URLs
+// as safe along with the error.
+var ErrThreatConfirmation = errors.New("failed to confirm threat list hash prefix match")
+
+// Validation rules reported in ValidationError.Rule
+const (
+	RuleFormat         = "format"
+	RuleScheme         = "scheme"
+	RuleHost           = "host"
+	RuleCredentials    = "credentials"
+	RuleDomainPolicy   = "domain_policy"
+	RuleResolution     = "resolution"
+	RulePrivateAddress = "private_address"
+	RuleRedirect       = "redirect"
+	// RuleThreatList is reported for URLs IsSafe rejects
+	RuleThreatList = "threat_list"
+)
+
+// ValidationError is returned when a URL is rejected. Rule identifies the
+// check that failed so callers can report it.
+type ValidationError struct {
+	Rule   string
+	Reason string
+	Match  string // Policy entry that matched, if any
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+func newValidationError(rule, reason string) *ValidationError {
+	return &ValidationError{Rule: rule, Reason: reason}
+}
+
+// UnsafeURLError is returned by IsSafe when a URL is on a threat list
+type UnsafeURLError struct {
+	Match ThreatMatch
+}
+
+func (e *UnsafeURLError) Error() string {
+	return fmt.Sprintf("URL is listed on threat list %s (matched %s)",
+		e.Match.List, e.Match.Expression)
+}
+
+// DeceptiveURLError is returned when a URL is rejected for its risk score
+type DeceptiveURLError struct {
+	Report *RiskReport
+}
+
+func (e *DeceptiveURLError) Error() string {
+	return fmt.Sprintf("URL looks deceptive (risk score %d)", e.Report.Score)
+}
@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/urlnorm"
+)
+
+// RedirectChain records where a URL ends up after following its redirects
+type RedirectChain struct {
+	Hops     []string // Every URL visited, starting with the original
+	FinalURL string
+}
+
+// RedirectFollower is implemented by validators that can resolve and vet a
+// URL's redirect chain before it is shortened
+type RedirectFollower interface {
+	FollowRedirects(ctx context.Context, rawURL string) (*RedirectChain, error)
+}
+
+// FollowRedirects issues HEAD (falling back to GET) requests along the
+// redirect chain of rawURL, running every hop through Validate and IsSafe.
+// It returns nil if redirect following is disabled.
+func (v *DefaultValidator) FollowRedirects(ctx context.Context, rawURL string) (*RedirectChain, error) {
+	if !v.followRedirects {
+		return nil, nil
+	}
+
+	if err := v.checkNotSelf(rawURL); err != nil {
+		return nil, err
+	}
+
+	chain := &RedirectChain{Hops: []string{rawURL}}
+	seen := map[string]bool{redirectKey(rawURL): true}
+	current := rawURL
+
+	for {
+		next, err := v.nextHop(ctx, current)
+		if err != nil {
+			return nil, newValidationError(RuleRedirect,
+				fmt.Sprintf("failed to follow %s: %v", current, err))
+		}
+		if next == "" {
+			chain.FinalURL = current
+			return chain, nil
+		}
+
+		if len(chain.Hops) > v.maxRedirects {
+			return nil, newValidationError(RuleRedirect,
+				fmt.Sprintf("more than %d redirects", v.maxRedirects))
+		}
+		if seen[redirectKey(next)] {
+			return nil, newValidationError(RuleRedirect,
+				fmt.Sprintf("redirect loop detected at %s", next))
+		}
+		seen[redirectKey(next)] = true
+
+		// Every hop has to pass the same checks as the original URL
+		if err := v.checkNotSelf(next); err != nil {
+			return nil, err
+		}
+		if err := v.Validate(next); err != nil {
+			return nil, fmt.Errorf("redirect to %s rejected: %w", next, err)
+		}
+		if safe, err := v.IsSafe(next); !safe {
+			if err == nil {
+				err = fmt.Errorf("URL is not safe")
+			}
+			return nil, fmt.Errorf("redirect to %s rejected: %w", next, err)
+		}
+
+		chain.Hops = append(chain.Hops, next)
+		current = next
+	}
+}
+
+// nextHop returns the absolute redirect target of rawURL, or "" if the
+// response is not a redirect
+func (v *DefaultValidator) nextHop(ctx context.Context, rawURL string) (string, error) {
+	resp, err := v.request(ctx, http.MethodHead, rawURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed ||
+		resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = v.request(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", nil
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", nil
+	}
+
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid Location header %q: %w", location, err)
+	}
+
+	return target.String(), nil
+}
+
+func (v *DefaultValidator) request(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "go-url-shortener-link-checker/1.0")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// Only the status line and headers matter
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+// checkNotSelf rejects URLs pointing back at this service, which would
+// create redirect loops through our own short links. Hosts are compared in
+// canonical form so https://sho.rt:443/ or SHO.RT./ match sho.rt too.
+func (v *DefaultValidator) checkNotSelf(rawURL string) error {
+	if v.selfHost == "" {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil // Validate reports malformed URLs
+	}
+	host, err := urlnorm.Host(u.Scheme, u.Host)
+	if err != nil {
+		return nil
+	}
+	if host == v.selfHost {
+		return newValidationError(RuleRedirect,
+			fmt.Sprintf("URL %s points back to this service", rawURL))
+	}
+	return nil
+}
+
+// redirectKey normalizes URLs enough to spot loops
+func redirectKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// newRedirectClient returns a client that never follows redirects itself
+// and, unless private networks are allowed, refuses to connect to blocked
+// addresses even if DNS changed since validation.
+func newRedirectClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			return checkAddr(host, addr)
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Resolver looks up the addresses of a host. *net.Resolver satisfies it;
+// tests can inject a static one to run offline.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+type blockedNetwork struct {
+	prefix netip.Prefix
+	name   string
+}
+
+// blockedNetworks are address ranges a shortened URL must never point at,
+// since following them would reach our own infrastructure.
+var blockedNetworks = []blockedNetwork{
+	{netip.MustParsePrefix("0.0.0.0/8"), "unspecified"},
+	{netip.MustParsePrefix("10.0.0.0/8"), "private (RFC 1918)"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "carrier-grade NAT"},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback"},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link-local"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "private (RFC 1918)"},
+	{netip.MustParsePrefix("192.0.0.0/24"), "IETF protocol assignment"},
+	{netip.MustParsePrefix("192.168.0.0/16"), "private (RFC 1918)"},
+	{netip.MustParsePrefix("198.18.0.0/15"), "benchmarking"},
+	{netip.MustParsePrefix("224.0.0.0/4"), "multicast"},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved"},
+	{netip.MustParsePrefix("::/128"), "unspecified"},
+	{netip.MustParsePrefix("::1/128"), "loopback"},
+	{netip.MustParsePrefix("fc00::/7"), "unique local"},
+	{netip.MustParsePrefix("fe80::/10"), "link-local"},
+	{netip.MustParsePrefix("ff00::/8"), "multicast"},
+}
+
+// nat64Prefix embeds IPv4 addresses in IPv6 (RFC 6052)
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// checkHost rejects hosts that are, or resolve to, non-public addresses
+func (v *DefaultValidator) checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return newValidationError(RulePrivateAddress,
+			fmt.Sprintf("host %s refers to the local machine", host))
+	}
+
+	if addr, ok := parseIPLiteral(host); ok {
+		return checkAddr(host, addr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.resolveTimeout)
+	defer cancel()
+
+	addrs, err := v.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return newValidationError(RuleResolution,
+			fmt.Sprintf("failed to resolve host %s: %v", host, err))
+	}
+	if len(addrs) == 0 {
+		return newValidationError(RuleResolution,
+			fmt.Sprintf("host %s has no addresses", host))
+	}
+
+	// Every address must be public, otherwise DNS could steer us inside
+	for _, ipAddr := range addrs {
+		addr, ok := netip.AddrFromSlice(ipAddr.IP)
+		if !ok {
+			continue
+		}
+		if err := checkAddr(host, addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkAddr(host string, addr netip.Addr) error {
+	if name, blocked := blockedAddr(addr); blocked {
+		return newValidationError(RulePrivateAddress,
+			fmt.Sprintf("host %s points to a %s address (%s)", host, name, addr.Unmap()))
+	}
+	return nil
+}
+
+func blockedAddr(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap().WithZone("")
+
+	if nat64Prefix.Contains(addr) {
+		raw := addr.As16()
+		addr = netip.AddrFrom4([4]byte{raw[12], raw[13], raw[14], raw[15]})
+	}
+
+	for _, network := range blockedNetworks {
+		if network.prefix.Contains(addr) {
+			return network.name, true
+		}
+	}
+	return "", false
+}
+
+// parseIPLiteral parses IP hosts, including the legacy IPv4 forms that
+// browsers and inet_aton accept: decimal (2130706433), octal (0177.0.0.1),
+// hex (0x7f.0.0.1) and shortened (127.1).
+func parseIPLiteral(host string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return addr, true
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, ok := parseIPv4Part(part)
+		if !ok {
+			return netip.Addr{}, false
+		}
+		values[i] = value
+	}
+
+	// The last part fills all remaining bytes
+	var ip uint64
+	for i, value := range values[:len(values)-1] {
+		if value > 0xff {
+			return netip.Addr{}, false
+		}
+		ip |= value << (8 * (3 - i))
+	}
+	last := values[len(values)-1]
+	if last >= 1<<(8*(5-len(values))) {
+		return netip.Addr{}, false
+	}
+	ip |= last
+
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), true
+}
+
+func parseIPv4Part(part string) (uint64, bool) {
+	if part == "" {
+		return 0, false
+	}
+
+	base := 10
+	switch {
+	case strings.HasPrefix(part, "0x") || strings.HasPrefix(part, "0X"):
+		base = 16
+		part = part[2:]
+		if part == "" {
+			return 0, true // "0x" alone is zero
+		}
+	case len(part) > 1 && part[0] == '0':
+		base = 8
+		part = part[1:]
+	}
+
+	value, err := strconv.ParseUint(part, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
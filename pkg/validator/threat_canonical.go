@@ -0,0 +1,226 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The functions below implement URL canonicalization and the host suffix /
+// path prefix expressions described in the Safe Browsing v4 "URLs and
+// Hashing" documentation, so local lists match the same way the API does.
+
+// canonicalizeThreatURL returns the canonical host, path and query of rawURL
+func canonicalizeThreatURL(rawURL string) (host, path, query string, err error) {
+	// Remove tab, CR and LF characters
+	s := strings.NewReplacer("\t", "", "\r", "", "\n", "").Replace(strings.TrimSpace(rawURL))
+
+	// Remove the fragment
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		s = s[:i]
+	}
+
+	s = unescapeRepeatedly(s)
+
+	// The scheme is not part of lookup expressions
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+
+	rest := ""
+	if i := strings.IndexAny(s, "/?"); i >= 0 {
+		s, rest = s[:i], s[i:]
+	}
+
+	host = canonicalThreatHost(s)
+	if host == "" {
+		return "", "", "", fmt.Errorf("URL %q has no host", rawURL)
+	}
+
+	path = rest
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		path, query = rest[:i], rest[i:]
+	}
+
+	return escapeThreatPart(host), escapeThreatPart(canonicalThreatPath(path)),
+		escapeThreatPart(query), nil
+}
+
+func canonicalThreatHost(hostPort string) string {
+	// Drop userinfo and port
+	if i := strings.LastIndexByte(hostPort, '@'); i >= 0 {
+		hostPort = hostPort[i+1:]
+	}
+	host := hostPort
+	if strings.HasPrefix(host, "[") {
+		if i := strings.IndexByte(host, ']'); i >= 0 {
+			host = host[:i+1]
+		}
+	} else if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+
+	host = strings.ToLower(strings.Trim(host, "."))
+	for strings.Contains(host, "..") {
+		host = strings.ReplaceAll(host, "..", ".")
+	}
+
+	// Normalize decimal, octal and hex IPv4 forms to dotted quads
+	if !strings.HasPrefix(host, "[") {
+		if addr, ok := parseIPLiteral(host); ok && addr.Is4() {
+			host = addr.String()
+		}
+	}
+
+	return host
+}
+
+func canonicalThreatPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, segment)
+		}
+	}
+
+	canonical := "/" + strings.Join(segments, "/")
+	trailingDir := strings.HasSuffix(path, "/") || strings.HasSuffix(path, "/.") ||
+		strings.HasSuffix(path, "/..")
+	if trailingDir && len(segments) > 0 {
+		canonical += "/"
+	}
+	return canonical
+}
+
+func unescapeRepeatedly(s string) string {
+	for i := 0; i < 1024; i++ {
+		unescaped := unescapeOnce(s)
+		if unescaped == s {
+			break
+		}
+		s = unescaped
+	}
+	return s
+}
+
+// unescapeOnce decodes valid %XX sequences and leaves invalid ones alone
+func unescapeOnce(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeThreatPart percent-escapes control characters, non-ASCII bytes,
+// '#' and '%'
+func escapeThreatPart(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 32 || c >= 127 || c == '#' || c == '%' {
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0x0f])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// threatExpressions returns the host suffix / path prefix combinations a
+// URL is looked up under, most specific first
+func threatExpressions(host, path, query string) []string {
+	var expressions []string
+	for _, h := range threatHostSuffixes(host) {
+		for _, p := range threatPathPrefixes(path, query) {
+			expressions = append(expressions, h+p)
+		}
+	}
+	return expressions
+}
+
+// threatHostSuffixes returns the exact host plus up to four hosts formed
+// from the last five components, dropping the top-level domain on its own.
+// IP addresses are only looked up exactly.
+func threatHostSuffixes(host string) []string {
+	hosts := []string{host}
+	if _, isIP := parseIPLiteral(host); isIP {
+		return hosts
+	}
+
+	parts := strings.Split(host, ".")
+	start := len(parts) - 5
+	if start < 1 {
+		start = 1
+	}
+	for i := start; i < len(parts)-1; i++ {
+		hosts = append(hosts, strings.Join(parts[i:], "."))
+	}
+	return hosts
+}
+
+// threatPathPrefixes returns the exact path with and without query plus up
+// to four directory prefixes starting at the root
+func threatPathPrefixes(path, query string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	if query != "" {
+		add(path + query)
+	}
+	add(path)
+
+	prefix := "/"
+	add(prefix)
+	dirs := path[1 : strings.LastIndexByte(path, '/')+1]
+	for i, dir := range strings.Split(strings.TrimSuffix(dirs, "/"), "/") {
+		if dir == "" || i >= 3 {
+			break
+		}
+		prefix += dir + "/"
+		add(prefix)
+	}
+
+	return paths
+}
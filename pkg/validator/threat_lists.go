@@ -0,0 +1,303 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/reloader"
+)
+
+// ThreatMatch identifies the list entry a URL matched
+type ThreatMatch struct {
+	List       string
+	Expression string
+}
+
+type ThreatListConfig struct {
+	// HashPrefixFiles hold Safe Browsing v4 threatListUpdates:fetch
+	// responses (FULL_UPDATE, RAW compression)
+	HashPrefixFiles []string
+	// DomainFiles and URLFiles list one domain / URL per line
+	DomainFiles []string
+	URLFiles    []string
+	// RefreshInterval is how often files are checked for changes
+	RefreshInterval time.Duration
+	// FullHashes confirms hits on hash prefixes shorter than a full
+	// SHA-256 digest. Without it every prefix hit is reported, which may
+	// flag a few safe URLs whose digest shares a listed prefix.
+	FullHashes FullHashFinder
+}
+
+// ThreatLists is an in-memory index of local threat feeds. Reloads build a
+// fresh index and swap it in atomically, so lookups never see a partial one.
+type ThreatLists struct {
+	config ThreatListConfig
+	index  atomic.Pointer[threatIndex]
+}
+
+type threatIndex struct {
+	// hashPrefixes maps prefix length to prefix to list name
+	hashPrefixes map[int]map[string]string
+	domains      map[string]string
+	urls         map[string]string
+}
+
+func NewThreatLists(config ThreatListConfig) (*ThreatLists, error) {
+	t := &ThreatLists{config: config}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload re-reads every list. On error the previous index stays in use.
+func (t *ThreatLists) Reload() error {
+	index := &threatIndex{
+		hashPrefixes: make(map[int]map[string]string),
+		domains:      make(map[string]string),
+		urls:         make(map[string]string),
+	}
+
+	for _, path := range t.config.HashPrefixFiles {
+		if err := index.loadHashPrefixFile(path); err != nil {
+			return fmt.Errorf("failed to load hash prefix list %s: %w", path, err)
+		}
+	}
+	for _, path := range t.config.DomainFiles {
+		if err := index.loadDomainFile(path); err != nil {
+			return fmt.Errorf("failed to load domain list %s: %w", path, err)
+		}
+	}
+	for _, path := range t.config.URLFiles {
+		if err := index.loadURLFile(path); err != nil {
+			return fmt.Errorf("failed to load URL list %s: %w", path, err)
+		}
+	}
+
+	t.index.Store(index)
+	return nil
+}
+
+// Watch reloads the lists when their files change or on SIGHUP until ctx
+// is cancelled. Reload errors are passed to onError.
+func (t *ThreatLists) Watch(ctx context.Context, onError func(error)) {
+	var paths []string
+	paths = append(paths, t.config.HashPrefixFiles...)
+	paths = append(paths, t.config.DomainFiles...)
+	paths = append(paths, t.config.URLFiles...)
+
+	reloader.Watch(ctx, t.config.RefreshInterval, paths, func() {
+		if err := t.Reload(); err != nil {
+			onError(err)
+		}
+	})
+}
+
+// Lookup returns the first list entry matching rawURL, or nil. Hash prefix
+// hits are confirmed by config.FullHashes when it is set and reported as
+// they are otherwise; confirmation failures wrap ErrThreatConfirmation.
+func (t *ThreatLists) Lookup(rawURL string) (*ThreatMatch, error) {
+	index := t.index.Load()
+
+	host, path, query, err := canonicalizeThreatURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range threatHostSuffixes(host) {
+		if list, ok := index.domains[h]; ok {
+			return &ThreatMatch{List: list, Expression: h}, nil
+		}
+	}
+
+	// Prefix hits that need confirmation, by full hash
+	var unconfirmed []prefixHit
+	for _, expression := range threatExpressions(host, path, query) {
+		if list, ok := index.urls[expression]; ok {
+			return &ThreatMatch{List: list, Expression: expression}, nil
+		}
+
+		hash := sha256.Sum256([]byte(expression))
+		if list, ok := index.hashPrefixes[sha256.Size][string(hash[:])]; ok {
+			return &ThreatMatch{List: list, Expression: expression}, nil
+		}
+		for size, prefixes := range index.hashPrefixes {
+			if size == sha256.Size {
+				continue
+			}
+			if list, ok := prefixes[string(hash[:size])]; ok {
+				unconfirmed = append(unconfirmed, prefixHit{
+					expression: expression,
+					hash:       string(hash[:]),
+					prefix:     string(hash[:size]),
+					list:       list,
+				})
+			}
+		}
+	}
+
+	if len(unconfirmed) == 0 {
+		return nil, nil
+	}
+	if t.config.FullHashes == nil {
+		hit := unconfirmed[0]
+		return &ThreatMatch{List: hit.list, Expression: hit.expression}, nil
+	}
+	return t.confirm(unconfirmed)
+}
+
+type prefixHit struct {
+	expression string
+	hash       string // Full SHA-256 digest of expression
+	prefix     string
+	list       string
+}
+
+// confirm asks config.FullHashes for the full digests behind the prefix
+// hits and returns the first hit whose digest is listed
+func (t *ThreatLists) confirm(hits []prefixHit) (*ThreatMatch, error) {
+	var prefixes, lists []string
+	for _, hit := range hits {
+		prefixes = append(prefixes, hit.prefix)
+		lists = append(lists, hit.list)
+	}
+
+	listed, err := t.config.FullHashes.FindFullHashes(prefixes, lists)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThreatConfirmation, err)
+	}
+
+	for _, hit := range hits {
+		if list, ok := listed[hit.hash]; ok {
+			return &ThreatMatch{List: list, Expression: hit.expression}, nil
+		}
+	}
+	return nil, nil
+}
+
+// threatListUpdates mirrors the JSON of a threatListUpdates:fetch response
+type threatListUpdates struct {
+	ListUpdateResponses []struct {
+		ThreatType   string `json:"threatType"`
+		ResponseType string `json:"responseType"`
+		Additions    []struct {
+			CompressionType string `json:"compressionType"`
+			RawHashes       *struct {
+				PrefixSize int    `json:"prefixSize"`
+				RawHashes  []byte `json:"rawHashes"`
+			} `json:"rawHashes"`
+		} `json:"additions"`
+		Checksum *struct {
+			SHA256 []byte `json:"sha256"`
+		} `json:"checksum"`
+	} `json:"listUpdateResponses"`
+}
+
+func (idx *threatIndex) loadHashPrefixFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var updates threatListUpdates
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return fmt.Errorf("invalid list update: %w", err)
+	}
+
+	for _, update := range updates.ListUpdateResponses {
+		if update.ResponseType != "" && update.ResponseType != "FULL_UPDATE" {
+			return fmt.Errorf("%s: only full updates are supported, got %s",
+				update.ThreatType, update.ResponseType)
+		}
+
+		var prefixes []string
+		for _, addition := range update.Additions {
+			if addition.CompressionType != "" && addition.CompressionType != "RAW" {
+				return fmt.Errorf("%s: unsupported compression %s",
+					update.ThreatType, addition.CompressionType)
+			}
+			if addition.RawHashes == nil {
+				continue
+			}
+
+			size := addition.RawHashes.PrefixSize
+			raw := addition.RawHashes.RawHashes
+			if size < 4 || size > sha256.Size || len(raw)%size != 0 {
+				return fmt.Errorf("%s: invalid prefix size %d for %d bytes",
+					update.ThreatType, size, len(raw))
+			}
+
+			if idx.hashPrefixes[size] == nil {
+				idx.hashPrefixes[size] = make(map[string]string)
+			}
+			for i := 0; i < len(raw); i += size {
+				prefix := string(raw[i : i+size])
+				idx.hashPrefixes[size][prefix] = update.ThreatType
+				prefixes = append(prefixes, prefix)
+			}
+		}
+
+		// The checksum is the SHA256 of all prefixes in lexicographic order
+		if update.Checksum != nil && len(update.Checksum.SHA256) > 0 {
+			sort.Strings(prefixes)
+			sum := sha256.Sum256([]byte(strings.Join(prefixes, "")))
+			if !bytes.Equal(sum[:], update.Checksum.SHA256) {
+				return fmt.Errorf("%s: checksum mismatch", update.ThreatType)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (idx *threatIndex) loadDomainFile(path string) error {
+	list := listName(path)
+	return readListLines(path, func(line string) {
+		idx.domains[canonicalThreatHost(line)] = list
+	})
+}
+
+func (idx *threatIndex) loadURLFile(path string) error {
+	list := listName(path)
+	return readListLines(path, func(line string) {
+		host, p, query, err := canonicalizeThreatURL(line)
+		if err == nil {
+			idx.urls[host+p+query] = list
+		}
+	})
+}
+
+// listName derives a list name from its file name, e.g. "phishing-domains"
+func listName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// readListLines calls fn for every non-empty line that isn't a '#' comment
+func readListLines(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeFullHashes struct {
+	listed map[string]string
+	err    error
+	calls  int
+}
+
+func (f *fakeFullHashes) FindFullHashes(prefixes, lists []string) (map[string]string, error) {
+	f.calls++
+	return f.listed, f.err
+}
+
+// writeHashList writes a threatListUpdates:fetch response listing the
+// prefixes of the given expressions
+func writeHashList(t *testing.T, size int, expressions ...string) string {
+	t.Helper()
+
+	var raw []byte
+	for _, expression := range expressions {
+		hash := sha256.Sum256([]byte(expression))
+		raw = append(raw, hash[:size]...)
+	}
+	data, err := json.Marshal(map[string]any{
+		"listUpdateResponses": []any{map[string]any{
+			"threatType":   "MALWARE",
+			"responseType": "FULL_UPDATE",
+			"additions": []any{map[string]any{
+				"compressionType": "RAW",
+				"rawHashes":       map[string]any{"prefixSize": size, "rawHashes": raw},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "malware.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func fullHash(expression string) string {
+	hash := sha256.Sum256([]byte(expression))
+	return string(hash[:])
+}
+
+func TestThreatListsHashPrefixNeedsConfirmation(t *testing.T) {
+	const expression = "evil.example/"
+	file := writeHashList(t, 4, expression)
+
+	tests := []struct {
+		name       string
+		fullHashes *fakeFullHashes
+		wantMatch  bool
+		wantErr    error
+	}{
+		{name: "no finder reports the prefix hit", wantMatch: true},
+		{
+			name:       "confirmed",
+			fullHashes: &fakeFullHashes{listed: map[string]string{fullHash(expression): "MALWARE"}},
+			wantMatch:  true,
+		},
+		{
+			name:       "other digest with same prefix",
+			fullHashes: &fakeFullHashes{listed: map[string]string{fullHash("other.example/"): "MALWARE"}},
+			wantMatch:  false,
+		},
+		{
+			name:       "finder error",
+			fullHashes: &fakeFullHashes{err: errors.New("unavailable")},
+			wantErr:    ErrThreatConfirmation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ThreatListConfig{HashPrefixFiles: []string{file}}
+			if tt.fullHashes != nil {
+				config.FullHashes = tt.fullHashes
+			}
+			lists, err := NewThreatLists(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			match, err := lists.Lookup("http://evil.example/")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lookup error = %v, want %v", err, tt.wantErr)
+			}
+			if (match != nil) != tt.wantMatch {
+				t.Fatalf("Lookup match = %+v, want match %v", match, tt.wantMatch)
+			}
+
+			safe, _ := NewValidator(Config{ThreatLists: lists}).IsSafe("http://evil.example/")
+			if safe == tt.wantMatch {
+				t.Errorf("IsSafe = %v, want %v", safe, !tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestThreatListsFullHashEntryMatches(t *testing.T) {
+	finder := &fakeFullHashes{}
+	lists, err := NewThreatLists(ThreatListConfig{
+		HashPrefixFiles: []string{writeHashList(t, sha256.Size, "evil.example/")},
+		FullHashes:      finder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match, err := lists.Lookup("http://evil.example/")
+	if err != nil || match == nil || match.List != "MALWARE" {
+		t.Fatalf("Lookup = %+v, %v; want MALWARE match", match, err)
+	}
+	if finder.calls != 0 {
+		t.Errorf("full hash entry needed %d confirmation calls, want 0", finder.calls)
+	}
+}
+
+func TestSafeBrowsingFullHashes(t *testing.T) {
+	hash := fullHash("evil.example/")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Goog-Api-Key") != "test-key" || r.URL.RawQuery != "" {
+			http.Error(w, "bad key", http.StatusForbidden)
+			return
+		}
+		var req fullHashesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.ThreatInfo.ThreatEntries) != 1 || string(req.ThreatInfo.ThreatEntries[0].Hash) != hash[:4] {
+			http.Error(w, "unexpected prefixes", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"matches": []any{map[string]any{
+			"threatType": "MALWARE",
+			"threat":     map[string]any{"hash": []byte(hash)},
+		}}})
+	}))
+	defer server.Close()
+
+	finder := NewSafeBrowsingFullHashes(SafeBrowsingConfig{APIKey: "test-key", Endpoint: server.URL})
+	listed, err := finder.FindFullHashes([]string{hash[:4]}, []string{"MALWARE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed[hash] != "MALWARE" {
+		t.Errorf("FindFullHashes = %v, want the digest listed as MALWARE", listed)
+	}
+}
+
+func TestSafeBrowsingFullHashesKeepsKeyOutOfErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close() // every request fails to connect
+
+	finder := NewSafeBrowsingFullHashes(SafeBrowsingConfig{APIKey: "secret-key", Endpoint: endpoint})
+	_, err := finder.FindFullHashes([]string{"abcd"}, []string{"MALWARE"})
+	if err == nil {
+		t.Fatal("FindFullHashes against a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("FindFullHashes error %q contains the API key", err)
+	}
+}
@@ -1,64 +1,181 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
-	"strings"
+	"time"
+
+	"github.com/umanagarjuna/go-url-shortener/pkg/urlnorm"
 )
 
 // URLValidator validates URLs
 type URLValidator interface {
 	Validate(rawURL string) error
 	IsSafe(rawURL string) (bool, error)
+	// Analyze scores how deceptive rawURL's presentation is
+	Analyze(rawURL string) (*RiskReport, error)
 }
 
 type DefaultValidator struct {
-	blacklistedDomains []string
-	maxRedirects       int
+	domainPolicy         *DomainPolicy
+	maxRedirects         int
+	followRedirects      bool
+	httpClient           *http.Client
+	selfHost             string
+	resolver             Resolver
+	resolveTimeout       time.Duration
+	allowPrivateNetworks bool
+	threatLists          *ThreatLists
+	protectedDomains     []string
+	maxSubdomainDepth    int
+}
+
+type Config struct {
+	// Resolver used for the private network check, net.DefaultResolver if nil
+	Resolver       Resolver
+	ResolveTimeout time.Duration
+	// AllowPrivateNetworks disables the SSRF check (local development only)
+	AllowPrivateNetworks bool
+	// ThreatLists backs IsSafe; every URL is considered safe if nil
+	ThreatLists *ThreatLists
+	// DomainPolicy decides which hosts may be shortened, denying
+	// DefaultDeniedDomains if nil
+	DomainPolicy *DomainPolicy
+
+	// FollowRedirects enables redirect chain resolution at creation time
+	FollowRedirects bool
+	MaxRedirects    int
+	RedirectTimeout time.Duration
+	// SelfURL is this service's base URL; chains leading back to it are
+	// rejected
+	SelfURL string
+	// HTTPClient overrides the client used to follow redirects. It must
+	// not follow redirects itself.
+	HTTPClient *http.Client
+
+	// ProtectedDomains are registrable domains that look-alikes are scored
+	// against, DefaultProtectedDomains if nil
+	ProtectedDomains []string
+	// MaxSubdomainDepth is the number of subdomain levels above the
+	// registrable domain tolerated by Analyze, 3 if unset
+	MaxSubdomainDepth int
 }
 
 func NewDefaultValidator() URLValidator {
+	return NewValidator(Config{})
+}
+
+func NewValidator(config Config) *DefaultValidator {
+	if config.Resolver == nil {
+		config.Resolver = net.DefaultResolver
+	}
+	if config.ResolveTimeout <= 0 {
+		config.ResolveTimeout = 2 * time.Second
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = 5
+	}
+	if config.RedirectTimeout <= 0 {
+		config.RedirectTimeout = 5 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = newRedirectClient(config.RedirectTimeout, config.AllowPrivateNetworks)
+	}
+	if config.ProtectedDomains == nil {
+		config.ProtectedDomains = DefaultProtectedDomains
+	}
+	if config.MaxSubdomainDepth <= 0 {
+		config.MaxSubdomainDepth = 3
+	}
+	if config.DomainPolicy == nil {
+		config.DomainPolicy, _ = NewDomainPolicy(DomainPolicyConfig{
+			Mode:  PolicyModeDeny,
+			Rules: DefaultDeniedDomains,
+		})
+	}
+
+	var selfHost string
+	if self, err := url.Parse(config.SelfURL); err == nil && self.Host != "" {
+		selfHost, _ = urlnorm.Host(self.Scheme, self.Host)
+	}
+
 	return &DefaultValidator{
-		blacklistedDomains: []string{
-			"bit.ly", "tinyurl.com", // Prevent recursive shortening
-		},
-		maxRedirects: 5,
+		domainPolicy:         config.DomainPolicy,
+		maxRedirects:         config.MaxRedirects,
+		followRedirects:      config.FollowRedirects,
+		httpClient:           config.HTTPClient,
+		selfHost:             selfHost,
+		resolver:             config.Resolver,
+		resolveTimeout:       config.ResolveTimeout,
+		allowPrivateNetworks: config.AllowPrivateNetworks,
+		threatLists:          config.ThreatLists,
+		protectedDomains:     config.ProtectedDomains,
+		maxSubdomainDepth:    config.MaxSubdomainDepth,
 	}
 }
 
 func (v *DefaultValidator) Validate(rawURL string) error {
 	if rawURL == "" {
-		return fmt.Errorf("URL cannot be empty")
+		return newValidationError(RuleFormat, "URL cannot be empty")
 	}
 
 	// Parse URL
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("invalid URL format: %w", err)
+		return newValidationError(RuleFormat, fmt.Sprintf("invalid URL format: %v", err))
 	}
 
 	// Check scheme
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return fmt.Errorf("only HTTP(S) URLs are allowed")
+		return newValidationError(RuleScheme, "only HTTP(S) URLs are allowed")
 	}
 
 	// Check host
 	if u.Host == "" {
-		return fmt.Errorf("URL must have a host")
+		return newValidationError(RuleHost, "URL must have a host")
+	}
+
+	// Credentials in the URL are mostly used to disguise the real host
+	if u.User != nil {
+		return newValidationError(RuleCredentials, "URLs with embedded credentials are not allowed")
+	}
+
+	// Check domain allow/deny rules
+	if err := v.domainPolicy.Check(u.Hostname()); err != nil {
+		return err
 	}
 
-	// Check blacklist
-	for _, domain := range v.blacklistedDomains {
-		if strings.Contains(u.Host, domain) {
-			return fmt.Errorf("domain %s is blacklisted", domain)
+	// Reject loopback, private and other internal destinations
+	if !v.allowPrivateNetworks {
+		if err := v.checkHost(u.Hostname()); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// IsSafe checks rawURL against the local threat lists. A listed URL is
+// reported as unsafe with an *UnsafeURLError naming the matching list.
 func (v *DefaultValidator) IsSafe(rawURL string) (bool, error) {
-	// In production, integrate with Google Safe Browsing API
-	// or similar service
+	if v.threatLists == nil {
+		return true, nil
+	}
+
+	match, err := v.threatLists.Lookup(rawURL)
+	if errors.Is(err, ErrThreatConfirmation) {
+		// An unconfirmed prefix hit is not evidence enough to block
+		return true, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("threat list lookup failed: %w", err)
+	}
+	if match != nil {
+		return false, &UnsafeURLError{Match: *match}
+	}
+
 	return true, nil
 }
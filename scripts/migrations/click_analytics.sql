@@ -0,0 +1,50 @@
+-- Event IDs of url.clicked events already counted, so redelivered events
+-- are not counted twice
+CREATE TABLE IF NOT EXISTS processed_click_events (
+    event_id VARCHAR(64) PRIMARY KEY,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_click_events_processed_at
+    ON processed_click_events (processed_at);
+
+-- Clicks per link and hour, broken down by referrer domain, country,
+-- device and browser
+CREATE TABLE IF NOT EXISTS click_stats_hourly (
+    short_code VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    referrer_domain VARCHAR(255) NOT NULL,
+    country VARCHAR(64) NOT NULL,
+    device VARCHAR(32) NOT NULL,
+    browser VARCHAR(64) NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_code, bucket, referrer_domain, country, device, browser)
+);
+
+-- Same breakdown per day (UTC)
+CREATE TABLE IF NOT EXISTS click_stats_daily (
+    short_code VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    referrer_domain VARCHAR(255) NOT NULL,
+    country VARCHAR(64) NOT NULL,
+    device VARCHAR(32) NOT NULL,
+    browser VARCHAR(64) NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_code, bucket, referrer_domain, country, device, browser)
+);
+
+-- HyperLogLog sketches (pkg/hll) of the visitors of a link per hour and
+-- per day, for unique-visitor estimates over any range
+CREATE TABLE IF NOT EXISTS click_uniques_hourly (
+    short_code VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    sketch BYTEA NOT NULL,
+    PRIMARY KEY (short_code, bucket)
+);
+
+CREATE TABLE IF NOT EXISTS click_uniques_daily (
+    short_code VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    sketch BYTEA NOT NULL,
+    PRIMARY KEY (short_code, bucket)
+);
@@ -32,3 +32,76 @@ CREATE UNIQUE INDEX CONCURRENTLY idx_urls_user_url_not_deleted
 CREATE INDEX CONCURRENTLY idx_urls_expired_active
     ON urls (expires_at, deleted_at)
     WHERE expires_at IS NOT NULL AND deleted_at IS NULL;
+
+-- Custom aliases can be longer than generated short codes
+ALTER TABLE urls ALTER COLUMN short_code TYPE VARCHAR(32);
+
+-- Pre-generated short codes handed out by the key pool; rows are deleted
+-- when a replica claims them
+CREATE TABLE IF NOT EXISTS short_code_pool (
+    short_code VARCHAR(32) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_code_pool_created_at
+    ON short_code_pool (created_at);
+
+-- Normalized form of original_url used for per-user dedupe; original_url is
+-- kept verbatim for redirects
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+
+-- Fill canonical_url for existing rows with the service's normalizer before
+-- creating the index below:
+--   go run ./cmd/backfill-canonical
+
+-- Run this command by itself (not in BEGIN/COMMIT block)
+CREATE UNIQUE INDEX CONCURRENTLY idx_urls_user_canonical_url_not_deleted
+    ON urls (user_id, canonical_url)
+    WHERE deleted_at IS NULL;
+
+-- Dedupe is keyed on canonical_url now; distinct URLs that share a canonical
+-- form must not collide on original_url
+DROP INDEX CONCURRENTLY IF EXISTS idx_urls_user_url_not_deleted;
+
+-- Before/after snapshots of every create and update of a URL
+CREATE TABLE IF NOT EXISTS url_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    changed_by VARCHAR(255),
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    before JSONB,
+    after JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (url_id, revision)
+);
+
+-- Transactional outbox: domain events written with the change they describe
+-- and published to Kafka by the relay
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    event_key VARCHAR(255) NOT NULL,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsent
+    ON outbox_events (id)
+    WHERE sent_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsent_key
+    ON outbox_events (topic, event_key, id)
+    WHERE sent_at IS NULL;
+
+-- Message headers of outbox events (content type, event id and type)
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS headers JSONB;
+
+-- Clicks classified as automated (link previews, crawlers, scanners) are
+-- counted apart from click_count
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS bot_click_count BIGINT NOT NULL DEFAULT 0;